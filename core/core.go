@@ -0,0 +1,46 @@
+// Package core implements the SHIELD backup orchestrator: scheduling,
+// running and tracking backup/restore tasks against targets, storing the
+// results as archives, and exposing all of it through the v2 API (see
+// v2.go) and, increasingly, other transports such as gRPC (see the
+// sibling grpc package).
+package core
+
+import (
+	"github.com/starkandwayne/shield/db"
+	"github.com/starkandwayne/shield/events"
+)
+
+// Core is the orchestrator itself: the DB handle it reads and writes
+// through, the providers (auth, notification, master-key sealing) it was
+// configured with, and the bookkeeping it needs to run scheduled tasks.
+//
+// Construct a Core with New and a list of Options; there is no exported
+// zero-value constructor, so that every Core is guaranteed to have gone
+// through option validation.
+type Core struct {
+	DB *db.DB
+
+	// Events is the bus GET /v2/events and GET /v2/system/:uuid/events
+	// stream from; DB write paths (task/archive annotation, agent
+	// pre-registration) publish to it. See events.Bus.
+	Events *events.Bus
+
+	auth []*AuthProviderConfig
+
+	providers providerRegistry
+
+	sealedWith string          // name of the SealBackend the core is currently sealed under
+	unseal     *unsealProgress // in-progress Shamir share accumulation for the active Unlock
+	dek        []byte          // data encryption key, held in memory only while unsealed
+}
+
+// AuthProviderConfig describes a single configured authentication
+// provider, as enumerated by GET /v2/auth/providers and
+// GET /v2/auth/provider/:name. It's registered with a Core via
+// WithAuthProvider.
+type AuthProviderConfig struct {
+	Name       string
+	Identifier string
+	Backend    string
+	Properties map[string]interface{}
+}
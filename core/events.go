@@ -0,0 +1,120 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pborman/uuid"
+
+	"github.com/starkandwayne/shield/db"
+	"github.com/starkandwayne/shield/events"
+	"github.com/starkandwayne/shield/route"
+)
+
+// streamEvents serves GET /v2/events (targetUUID == "") and
+// GET /v2/system/:uuid/events (targetUUID set) by upgrading r to a
+// text/event-stream response: it replays whatever the caller missed
+// since Last-Event-ID out of core.Events' ring buffer, then blocks,
+// writing each subsequent event as it's published, until the client
+// disconnects.
+//
+// Which events a caller sees is scoped by their token: a token bound to
+// db.SystemTenantName sees every tenant's task/archive events (plus
+// agent events, which aren't tenant-scoped); any other token sees only
+// its own tenant's. When targetUUID is set, events for other systems are
+// additionally filtered out.
+func (core *Core) streamEvents(r *route.Request, targetUUID string) {
+	token, ok := core.authorize(r, db.RoleBackupOperator, db.RoleRestoreOperator, db.RoleTenantAdmin, db.RoleReadOnly)
+	if !ok {
+		return
+	}
+
+	if targetUUID != "" {
+		target, err := core.DB.GetTarget(uuid.Parse(targetUUID))
+		if err != nil {
+			r.Fail(route.Oops(err, "failed to stream events for system %s", targetUUID))
+			return
+		}
+		if target == nil {
+			r.Fail(route.NotFound(nil, "system %s not found", targetUUID))
+			return
+		}
+		if owns, err := core.ownsTarget(token, target); err != nil {
+			r.Fail(route.Oops(err, "failed to stream events for system %s", targetUUID))
+			return
+		} else if !owns {
+			r.Fail(route.NotFound(nil, "system %s not found", targetUUID))
+			return
+		}
+	}
+
+	tenant, err := core.DB.GetTenant(token.TenantUUID)
+	if err != nil {
+		r.Fail(route.Oops(err, "failed to stream events"))
+		return
+	}
+
+	patterns := []string{"agent.*"}
+	if tenant != nil && tenant.Name == db.SystemTenantName {
+		patterns = append(patterns, "task.*", "archive.*")
+	} else {
+		patterns = append(patterns, "tenant."+token.TenantUUID.String()+".*")
+	}
+
+	flusher, ok := r.Res.(http.Flusher)
+	if !ok {
+		r.Fail(route.Oops(nil, "unable to stream events: response writer does not support flushing"))
+		return
+	}
+
+	wantsEvent := func(ev events.Event) bool {
+		return targetUUID == "" || ev.Target == "" || ev.Target == targetUUID
+	}
+	send := func(ev events.Event) bool {
+		if !wantsEvent(ev) {
+			return true
+		}
+		payload, err := json.Marshal(ev)
+		if err != nil {
+			return true
+		}
+		if _, err := fmt.Fprintf(r.Res, "id: %d\ndata: %s\n\n", ev.ID, payload); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	r.Res.Header().Set("Content-Type", "text/event-stream")
+	r.Res.Header().Set("Cache-Control", "no-cache")
+	r.Res.Header().Set("Connection", "keep-alive")
+	r.Res.WriteHeader(http.StatusOK)
+
+	var lastID uint64
+	if id := r.Req.Header.Get("Last-Event-ID"); id != "" {
+		fmt.Sscanf(id, "%d", &lastID)
+	}
+	for _, ev := range core.Events.Replay(lastID, patterns...) {
+		if !send(ev) {
+			return
+		}
+	}
+
+	sub := core.Events.Subscribe(patterns...)
+	defer sub.Close()
+
+	for {
+		select {
+		case ev, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if !send(ev) {
+				return
+			}
+		case <-r.Req.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/starkandwayne/shield/db"
+)
+
+// CheckHealth is the exported form of checkHealth, for transports (like
+// the gRPC CoreService) that live outside package core and so can't call
+// the unexported method the HTTP v2 API uses directly.
+func (core *Core) CheckHealth() (interface{}, error) {
+	return core.checkHealth()
+}
+
+// ValidateSessionToken resolves a gRPC bearer token to the scoped
+// db.APIToken it was minted as. There's no separate login/session flow
+// for this transport: it's the same tenant-scoped bearer tokens minted
+// by POST /v2/tenant/:uuid/tokens that the HTTP v2 API's authorize
+// checks against, so a token works across both transports interchangeably.
+func (core *Core) ValidateSessionToken(token string) (*db.APIToken, error) {
+	t, err := core.DB.GetAPITokenBySecret(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate API token: %s", err)
+	}
+	if t == nil {
+		return nil, fmt.Errorf("unknown or expired API token")
+	}
+	return t, nil
+}
+
+// AuthorizeToken is the exported form of authorizeToken, for transports
+// (like the gRPC CoreService) that resolve their token from the call
+// context instead of an HTTP Authorization header.
+func (core *Core) AuthorizeToken(token *db.APIToken, allowed ...string) (bool, error) {
+	return core.authorizeToken(token, allowed...)
+}
+
+// OwnsTarget is the exported form of ownsTarget, for transports outside
+// package core.
+func (core *Core) OwnsTarget(token *db.APIToken, target *db.Target) (bool, error) {
+	return core.ownsTarget(token, target)
+}
+
+// apiCallCounts tracks per-tenant, per-method call counts observed by the
+// gRPC metrics interceptors, the streaming-aware analogue of whatever
+// request counters the HTTP listener already keeps.
+var apiCallCounts = struct {
+	mu     sync.Mutex
+	counts map[string]map[string]uint64 // tenant -> method -> count
+}{counts: make(map[string]map[string]uint64)}
+
+// CountAPICall increments the call counter for (tenant, method). tenant
+// may be empty for calls made outside of any tenant's scope.
+func (core *Core) CountAPICall(tenant, method string) {
+	apiCallCounts.mu.Lock()
+	defer apiCallCounts.mu.Unlock()
+
+	if apiCallCounts.counts[tenant] == nil {
+		apiCallCounts.counts[tenant] = make(map[string]uint64)
+	}
+	apiCallCounts.counts[tenant][method]++
+}
+
+// APICallCounts returns a snapshot of the per-tenant call counters
+// recorded by CountAPICall.
+func (core *Core) APICallCounts() map[string]map[string]uint64 {
+	apiCallCounts.mu.Lock()
+	defer apiCallCounts.mu.Unlock()
+
+	out := make(map[string]map[string]uint64, len(apiCallCounts.counts))
+	for tenant, methods := range apiCallCounts.counts {
+		m := make(map[string]uint64, len(methods))
+		for method, n := range methods {
+			m[method] = n
+		}
+		out[tenant] = m
+	}
+	return out
+}
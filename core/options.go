@@ -0,0 +1,175 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/starkandwayne/shield/db"
+	"github.com/starkandwayne/shield/events"
+)
+
+// Option configures a Core during construction by core.New. Options are
+// applied in the order they're passed, so later options can override
+// earlier ones (e.g. a second WithAuthProvider for the same identifier
+// replaces the first).
+type Option interface {
+	apply(*Core) error
+}
+
+type optionFunc func(*Core) error
+
+func (f optionFunc) apply(core *Core) error { return f(core) }
+
+// WithDB sets the database handle a Core uses to read and write targets,
+// tasks, archives, agents and tenants.
+func WithDB(d *db.DB) Option {
+	return optionFunc(func(core *Core) error {
+		core.DB = d
+		return nil
+	})
+}
+
+// WithAuthProvider registers an authentication provider under its own
+// Identifier, replacing any provider previously registered under that
+// identifier. Providers registered this way are what GET /v2/auth/providers
+// and GET /v2/auth/provider/:name enumerate.
+func WithAuthProvider(provider *AuthProviderConfig) Option {
+	return optionFunc(func(core *Core) error {
+		if provider.Identifier == "" {
+			return fmt.Errorf("auth provider is missing an identifier")
+		}
+		core.providers.auth[provider.Identifier] = provider
+		return nil
+	})
+}
+
+// WithNotifier registers a task notifier (Slack, webhook, email, ...)
+// under name, replacing any notifier previously registered under that
+// name. Registered notifiers are only held in the Core's provider
+// registry; nothing in package core invokes them as tasks complete.
+func WithNotifier(name string, notifier Notifier) Option {
+	return optionFunc(func(core *Core) error {
+		if name == "" {
+			return fmt.Errorf("notifier is missing a name")
+		}
+		core.providers.notifiers[name] = notifier
+		return nil
+	})
+}
+
+// WithMasterKeyProvider registers a SealBackend under name, replacing any
+// backend previously registered under that name. The registered name is
+// what POST /v2/init accepts as its `backend` field and POST /v2/rekey as
+// its `to_backend` field; POST /v2/unlock always uses the backend the
+// core is currently sealed under.
+func WithMasterKeyProvider(name string, backend SealBackend) Option {
+	return optionFunc(func(core *Core) error {
+		if name == "" {
+			return fmt.Errorf("master key provider is missing a name")
+		}
+		core.providers.seals[name] = backend
+		return nil
+	})
+}
+
+// WithStoragePlugin registers the StorePlugin that implements the
+// storage-plugin protocol for archives stored under plugin name (e.g.
+// "s3", "azure", "fs"), replacing any plugin previously registered under
+// that name. The background archive-sizing worker (see sizer.go) uses
+// this registry to resolve an archive's store_plugin to something it
+// can call Stat on.
+func WithStoragePlugin(name string, plugin StorePlugin) Option {
+	return optionFunc(func(core *Core) error {
+		if name == "" {
+			return fmt.Errorf("storage plugin is missing a name")
+		}
+		core.providers.storePlugins[name] = plugin
+		return nil
+	})
+}
+
+// providerRegistry holds everything integrators can plug into a Core via
+// options, keyed by the name/identifier they registered under.
+type providerRegistry struct {
+	auth         map[string]*AuthProviderConfig
+	notifiers    map[string]Notifier
+	seals        map[string]SealBackend
+	storePlugins map[string]StorePlugin
+}
+
+func newProviderRegistry() providerRegistry {
+	return providerRegistry{
+		auth:         make(map[string]*AuthProviderConfig),
+		notifiers:    make(map[string]Notifier),
+		seals:        make(map[string]SealBackend),
+		storePlugins: make(map[string]StorePlugin),
+	}
+}
+
+// New builds a Core from the given options. Options are applied in
+// order; WithDB (or an equivalent option supplying core.DB) must be
+// among them for the returned Core to be usable.
+func New(opts ...Option) (*Core, error) {
+	core := &Core{
+		providers: newProviderRegistry(),
+		unseal:    &unsealProgress{},
+		Events:    events.NewBus(),
+	}
+	core.providers.seals[defaultSealBackend] = NewLocalSealBackend(1, 1)
+
+	for _, opt := range opts {
+		if err := opt.apply(core); err != nil {
+			return nil, fmt.Errorf("failed to configure SHIELD core: %s", err)
+		}
+	}
+
+	core.auth = make([]*AuthProviderConfig, 0, len(core.providers.auth))
+	for _, provider := range core.providers.auth {
+		core.auth = append(core.auth, provider)
+	}
+	sort.Slice(core.auth, func(i, j int) bool {
+		return core.auth[i].Identifier < core.auth[j].Identifier
+	})
+
+	return core, nil
+}
+
+// Notifier delivers a notification about a completed or failed task (via
+// Slack, a generic webhook, email, etc). Implementations are registered
+// with WithNotifier.
+type Notifier interface {
+	Notify(task *db.Task) error
+}
+
+// SealBackend wraps a master-key sealing mechanism (local passphrase,
+// Vault transit, AWS KMS, a PKCS#11 HSM, ...) pluggable via
+// WithMasterKeyProvider. Name identifies the backend in the persisted
+// seal state; register a backend under the same name its Name returns,
+// since Unlock and Rekey look the sealing backend up by that name.
+// See seal.go for the concrete backends.
+type SealBackend interface {
+	Name() string
+
+	// Threshold is how many key shares Unseal needs submitted before it
+	// reconstitutes this backend's master key. Backends that manage
+	// their own sealing (Vault transit, KMS, a PKCS#11 HSM) return 1;
+	// only the local passphrase backend's Shamir split returns more.
+	Threshold() int
+
+	// Seal wraps a freshly generated data encryption key under this
+	// backend's master key, using params (what POST /v2/init passes in
+	// its `master_password` field; only the local backend uses it). It
+	// returns the wrapped DEK to be persisted as the core's seal state.
+	Seal(dek, params []byte) (wrapped []byte, err error)
+
+	// Unseal unwraps wrapped and returns the DEK, given the backend's
+	// whole secret in share (for the local backend, the passphrase; a
+	// Shamir split is first reassembled by Core.Unlock, not here). On
+	// success progress is the backend's threshold.
+	Unseal(wrapped, share []byte) (dek []byte, progress int, err error)
+
+	// Rewrap re-wraps wrapped, which was sealed under this backend, under
+	// this backend's current key material. RekeyToBackend does not call
+	// it; it unseals under the old backend and seals under the new one.
+	Rewrap(wrapped, params []byte) (rewrapped []byte, err error)
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/hashicorp/vault/shamir"
+)
+
+// localSealBackend is today's behavior: a single passphrase, split into
+// N shares (via Shamir's Secret Sharing) so operators can require more
+// than one person to unlock a core. Threshold() of 1 reproduces the
+// pre-existing single-master-password flow exactly.
+type localSealBackend struct {
+	shares    int
+	threshold int
+}
+
+// NewLocalSealBackend builds the default passphrase-backed SealBackend.
+// With shares == threshold == 1 (the zero-configuration default) it
+// behaves exactly like the master password SHIELD has always used.
+func NewLocalSealBackend(shares, threshold int) SealBackend {
+	if shares < 1 {
+		shares = 1
+	}
+	if threshold < 1 || threshold > shares {
+		threshold = 1
+	}
+	return &localSealBackend{shares: shares, threshold: threshold}
+}
+
+func (b *localSealBackend) Name() string   { return "local" }
+func (b *localSealBackend) Threshold() int { return b.threshold }
+
+func (b *localSealBackend) Seal(dek, params []byte) ([]byte, error) {
+	key := sha256.Sum256(params) // params carries the master passphrase
+	return aesSeal(key[:], dek)
+}
+
+func (b *localSealBackend) Unseal(wrapped, share []byte) ([]byte, int, error) {
+	key := sha256.Sum256(share) // share carries the master passphrase
+	dek, err := aesUnseal(key[:], wrapped)
+	if err != nil {
+		return nil, 0, err
+	}
+	return dek, b.threshold, nil
+}
+
+func (b *localSealBackend) Rewrap(wrapped, params []byte) ([]byte, error) {
+	return nil, fmt.Errorf("local seal backend does not support direct rewrap; unseal then reseal")
+}
+
+// splitPassphrase divides a master passphrase into b.shares Shamir
+// shares, b.threshold of which are required to reconstitute it. It's
+// exposed for the /v2/init handler to hand shares back to the operator
+// at initialization time, since SHIELD itself never persists them.
+func (b *localSealBackend) splitPassphrase(passphrase []byte) ([][]byte, error) {
+	if b.shares == 1 {
+		return [][]byte{passphrase}, nil
+	}
+	return shamir.Split(passphrase, b.shares, b.threshold)
+}
+
+func aesSeal(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func aesUnseal(key, wrapped []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < gcm.NonceSize() {
+		return nil, fmt.Errorf("sealed data is truncated")
+	}
+	nonce, ciphertext := wrapped[:gcm.NonceSize()], wrapped[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
@@ -0,0 +1,205 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/starkandwayne/shield/db"
+)
+
+// dekSize is the size, in bytes, of the data encryption key each
+// SealBackend wraps. Archive keys stored in the DB are themselves
+// encrypted under this DEK, not under a backend's master key directly,
+// so that Rekey only ever needs to re-wrap one key.
+const dekSize = 32
+
+// defaultSealBackend is "local", preserving the pre-existing
+// single-master-password behavior for callers (and tests) that don't
+// specify a backend.
+const defaultSealBackend = "local"
+
+func (core *Core) sealBackend(name string) (SealBackend, error) {
+	if name == "" {
+		name = defaultSealBackend
+	}
+	b, ok := core.providers.seals[name]
+	if !ok {
+		return nil, fmt.Errorf("unrecognized seal backend '%s'", name)
+	}
+	return b, nil
+}
+
+// Initialize generates a new DEK and seals it under the local passphrase
+// backend, persisting the result. It returns false, rather than an error, if this core has
+// already been initialized — the same "already initialized" signal the
+// pre-refactor Initialize gave callers.
+func (core *Core) Initialize(masterPassword string) (bool, error) {
+	ok, _, err := core.InitializeWithBackend(defaultSealBackend, []byte(masterPassword))
+	return ok, err
+}
+
+// shareSplitter is implemented by SealBackend backends whose master key
+// material is distributed across multiple operators instead of held by
+// one (today, only a local backend configured with threshold > 1).
+// InitializeWithBackend uses it to hand the Shamir shares back to the
+// caller once, at initialization time, since SHIELD itself never
+// persists them.
+type shareSplitter interface {
+	splitPassphrase(passphrase []byte) ([][]byte, error)
+}
+
+// InitializeWithBackend is the backend-aware form of Initialize, used by
+// POST /v2/init's `backend` field to seal under Vault, KMS or a PKCS#11
+// HSM instead of a local passphrase. params is the backend's secret
+// material: the master passphrase for the local backend, ignored by
+// backends (Vault, KMS, PKCS#11) whose key material is wired in at
+// process startup via WithMasterKeyProvider instead.
+//
+// If the selected backend splits its secret into multiple Shamir
+// shares (threshold > 1), the returned shares must be distributed to
+// operators out of band; Unlock later requires threshold of them to be
+// POSTed back before it will reconstitute the DEK.
+func (core *Core) InitializeWithBackend(backend string, params []byte) (bool, [][]byte, error) {
+	existing, err := core.DB.GetSealState()
+	if err != nil {
+		return false, nil, err
+	}
+	if existing != nil {
+		return false, nil, nil
+	}
+
+	b, err := core.sealBackend(backend)
+	if err != nil {
+		return false, nil, err
+	}
+
+	dek := make([]byte, dekSize)
+	if _, err := rand.Read(dek); err != nil {
+		return false, nil, fmt.Errorf("failed to generate data encryption key: %s", err)
+	}
+
+	wrapped, err := b.Seal(dek, params)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if err := core.DB.SetSealState(&db.SealState{Backend: b.Name(), Wrapped: wrapped}); err != nil {
+		return false, nil, err
+	}
+	core.sealedWith = b.Name()
+
+	var shares [][]byte
+	if b.Threshold() > 1 {
+		splitter, ok := b.(shareSplitter)
+		if !ok {
+			return false, nil, fmt.Errorf("seal backend '%s' reports a threshold of %d but does not support splitting its secret", b.Name(), b.Threshold())
+		}
+		shares, err = splitter.splitPassphrase(params)
+		if err != nil {
+			return false, nil, fmt.Errorf("failed to split master passphrase into key shares: %s", err)
+		}
+	}
+
+	return true, shares, nil
+}
+
+// Unlock submits share toward reconstituting the DEK under whichever
+// backend this core is currently sealed with. For single-share backends
+// (Vault, KMS, PKCS#11, or a local backend configured with threshold 1)
+// this unseals on the first call; for a local backend split into N
+// Shamir shares, Unlock accumulates shares across calls and only
+// unwraps the DEK once SealBackend.Threshold() has been met. Such shares
+// are submitted base64-encoded, as InitializeWithBackend's shares are
+// handed out by POST /v2/init.
+func (core *Core) Unlock(share string) (bool, error) {
+	state, err := core.DB.GetSealState()
+	if err != nil {
+		return false, err
+	}
+	if state == nil {
+		return false, nil
+	}
+
+	b, err := core.sealBackend(state.Backend)
+	if err != nil {
+		return false, err
+	}
+
+	raw := []byte(share)
+	if b.Threshold() > 1 {
+		if raw, err = base64.StdEncoding.DecodeString(share); err != nil {
+			return false, fmt.Errorf("key share is not valid base64: %s", err)
+		}
+	}
+	progress := core.unseal.add(raw)
+	if progress < b.Threshold() {
+		return true, nil
+	}
+
+	combined, err := core.unseal.combined()
+	if err != nil {
+		core.unseal.reset()
+		return false, fmt.Errorf("failed to unlock the SHIELD core: %s", err)
+	}
+
+	dek, _, err := b.Unseal(state.Wrapped, combined)
+	core.unseal.reset()
+	if err != nil {
+		return false, fmt.Errorf("failed to unlock the SHIELD core: %s", err)
+	}
+
+	core.sealedWith = b.Name()
+	core.dek = dek
+	return true, nil
+}
+
+// Rekey re-wraps the DEK under newParams on the backend it is currently
+// sealed with, rotating the master passphrase in place (RekeyToBackend
+// can also move it to another backend): archive keys stored in the DB are
+// themselves only ever wrapped under the DEK, so Rekey never has to
+// touch them.
+func (core *Core) Rekey(curParams, newParams string) error {
+	return core.RekeyToBackend([]byte(curParams), "", []byte(newParams))
+}
+
+// RekeyToBackend is the backend-migrating form of Rekey, used by
+// POST /v2/rekey's `to_backend` field. An empty toBackend rekeys in
+// place, rotating the passphrase/params without changing backends.
+func (core *Core) RekeyToBackend(curParams []byte, toBackend string, newParams []byte) error {
+	state, err := core.DB.GetSealState()
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("this SHIELD core has not yet been initialized")
+	}
+	if toBackend == "" {
+		toBackend = state.Backend
+	}
+
+	from, err := core.sealBackend(state.Backend)
+	if err != nil {
+		return err
+	}
+	dek, _, err := from.Unseal(state.Wrapped, curParams)
+	if err != nil {
+		return fmt.Errorf("failed to unlock the SHIELD core for rekey: %s", err)
+	}
+
+	to, err := core.sealBackend(toBackend)
+	if err != nil {
+		return err
+	}
+	wrapped, err := to.Seal(dek, newParams)
+	if err != nil {
+		return fmt.Errorf("failed to rekey the SHIELD core: %s", err)
+	}
+
+	if err := core.DB.SetSealState(&db.SealState{Backend: to.Name(), Wrapped: wrapped}); err != nil {
+		return err
+	}
+	core.sealedWith = to.Name()
+	core.dek = dek
+	return nil
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	vaultapi "github.com/hashicorp/vault/api"
+)
+
+// vaultSealBackend wraps the DEK using HashiCorp Vault's transit secrets
+// engine, so the actual master key never leaves Vault. Threshold is
+// always 1: Vault handles its own unsealing upstream of us.
+type vaultSealBackend struct {
+	client *vaultapi.Client
+	mount  string // transit mount point, e.g. "transit"
+	key    string // transit key name, e.g. "shield"
+}
+
+// NewVaultSealBackend builds a SealBackend backed by client's transit
+// engine at mount, using transit key name key (created if it doesn't
+// already exist).
+func NewVaultSealBackend(client *vaultapi.Client, mount, key string) SealBackend {
+	return &vaultSealBackend{client: client, mount: mount, key: key}
+}
+
+func (b *vaultSealBackend) Name() string   { return "vault" }
+func (b *vaultSealBackend) Threshold() int { return 1 }
+
+func (b *vaultSealBackend) Seal(dek, _ []byte) ([]byte, error) {
+	secret, err := b.client.Logical().WriteWithContext(context.Background(),
+		fmt.Sprintf("%s/encrypt/%s", b.mount, b.key),
+		map[string]interface{}{"plaintext": base64.StdEncoding.EncodeToString(dek)},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("vault transit encrypt failed: %s", err)
+	}
+	ciphertext, ok := secret.Data["ciphertext"].(string)
+	if !ok {
+		return nil, fmt.Errorf("vault transit encrypt returned no ciphertext")
+	}
+	return []byte(ciphertext), nil
+}
+
+func (b *vaultSealBackend) Unseal(wrapped, _ []byte) ([]byte, int, error) {
+	secret, err := b.client.Logical().WriteWithContext(context.Background(),
+		fmt.Sprintf("%s/decrypt/%s", b.mount, b.key),
+		map[string]interface{}{"ciphertext": string(wrapped)},
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("vault transit decrypt failed: %s", err)
+	}
+	encoded, ok := secret.Data["plaintext"].(string)
+	if !ok {
+		return nil, 0, fmt.Errorf("vault transit decrypt returned no plaintext")
+	}
+	dek, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, 0, err
+	}
+	return dek, 1, nil
+}
+
+func (b *vaultSealBackend) Rewrap(wrapped, _ []byte) ([]byte, error) {
+	secret, err := b.client.Logical().WriteWithContext(context.Background(),
+		fmt.Sprintf("%s/rewrap/%s", b.mount, b.key),
+		map[string]interface{}{"ciphertext": string(wrapped)},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("vault transit rewrap failed: %s", err)
+	}
+	ciphertext, ok := secret.Data["ciphertext"].(string)
+	if !ok {
+		return nil, fmt.Errorf("vault transit rewrap returned no ciphertext")
+	}
+	return []byte(ciphertext), nil
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"time"
+
+	"github.com/starkandwayne/goutils/log"
+)
+
+// sizerBatchSize is how many unsized archives StartSizer pulls per pass.
+const sizerBatchSize = 25
+
+// sizerPerStoreDelay is how long StartSizer waits between stat calls
+// against the same store, so a sizing pass doesn't hammer a backend
+// (S3, Azure, ...) that's also serving live backup/restore traffic.
+const sizerPerStoreDelay = 250 * time.Millisecond
+
+// StartSizer runs, until stop is closed, a background loop that stats
+// archives whose size is still unknown (see the `Size: -1 // FIXME` that
+// used to be permanent in GET /v2/system/:uuid) against their store's
+// plugin, rate-limited per store, and caches the result in the DB.
+func (core *Core) StartSizer(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			core.sizePass()
+		}
+	}
+}
+
+func (core *Core) sizePass() {
+	archives, err := core.DB.GetArchivesWithUnknownSize(sizerBatchSize)
+	if err != nil {
+		log.Errorf("sizer: failed to list archives with unknown size: %s", err)
+		return
+	}
+
+	lastCallAt := make(map[string]time.Time)
+	for _, archive := range archives {
+		if since := time.Since(lastCallAt[archive.StoreUUID.String()]); since < sizerPerStoreDelay {
+			time.Sleep(sizerPerStoreDelay - since)
+		}
+		lastCallAt[archive.StoreUUID.String()] = time.Now()
+
+		plugin, ok := core.providers.storePlugins[archive.StorePlugin]
+		if !ok {
+			log.Debugf("sizer: no storage plugin registered for '%s'; skipping archive %s", archive.StorePlugin, archive.UUID)
+			continue
+		}
+
+		size, err := plugin.Stat(archive.StoreEndpoint)
+		if err != nil {
+			log.Errorf("sizer: failed to stat archive %s: %s", archive.UUID, err)
+			continue
+		}
+
+		if err := core.DB.SetArchiveSize(archive.UUID, size); err != nil {
+			log.Errorf("sizer: failed to record size for archive %s: %s", archive.UUID, err)
+		}
+	}
+}
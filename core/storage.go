@@ -0,0 +1,54 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// StorePlugin is the part of the shield-agent storage-plugin protocol
+// core needs directly (as opposed to delegating to an agent to run a
+// backup/restore): asking a plugin binary to report how large an
+// archive is in its backend, via the `stat` verb.
+//
+// `stat` is invoked the same way every other plugin verb is: the plugin
+// binary is exec'd with the verb as its first argument and the
+// endpoint configuration as a JSON document on stdin, and it prints a
+// JSON result to stdout. For `stat` that result is {"bytes": <int64>} —
+// an S3 plugin does this via HeadObject, Azure via GetBlobProperties,
+// and the local filesystem plugin via os.Stat.
+type StorePlugin interface {
+	Stat(endpoint string) (bytes int64, err error)
+}
+
+// execStorePlugin invokes a plugin binary on disk to satisfy StorePlugin.
+type execStorePlugin struct {
+	path string
+}
+
+// NewStorePlugin returns a StorePlugin that execs the plugin binary at
+// path (e.g. the "s3", "azure" or "fs" plugin); path is used as given.
+func NewStorePlugin(path string) StorePlugin {
+	return &execStorePlugin{path: path}
+}
+
+type statResult struct {
+	Bytes int64 `json:"bytes"`
+}
+
+func (p *execStorePlugin) Stat(endpoint string) (int64, error) {
+	cmd := exec.Command(p.path, "stat")
+	cmd.Stdin = bytes.NewBufferString(endpoint)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("%s stat failed: %s", p.path, err)
+	}
+
+	var result statResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		return 0, fmt.Errorf("%s stat returned malformed output: %s", p.path, err)
+	}
+	return result.Bytes, nil
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"time"
+
+	"github.com/pborman/uuid"
+	"github.com/starkandwayne/goutils/log"
+
+	"github.com/starkandwayne/shield/db"
+)
+
+// eventWatchPollInterval mirrors watchTasksPollInterval: how often
+// StartEventWatcher re-checks the DB for task and archive changes to
+// publish onto core.Events, since nothing in this tree pushes those
+// changes to us directly (no DB-level LISTEN/NOTIFY).
+const eventWatchPollInterval = 2 * time.Second
+
+// StartEventWatcher runs, until stop is closed, a background loop that
+// publishes a "task.<status>" event each time a task's status changes,
+// and "archive.created"/"archive.expired" events as archives appear or
+// expire, so GET /v2/events and GET /v2/system/:uuid/events carry live
+// backup/restore progress instead of only the events the PATCH-annotation
+// handlers publish after calling AnnotateTargetTask/AnnotateTargetArchive.
+func (core *Core) StartEventWatcher(stop <-chan struct{}) {
+	taskStatus := core.seedTaskStatus()
+	archiveStatus := core.seedArchiveStatus()
+
+	ticker := time.NewTicker(eventWatchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			core.publishTaskTransitions(taskStatus)
+			core.publishArchiveTransitions(archiveStatus)
+		}
+	}
+}
+
+func (core *Core) publishTaskTransitions(seen map[string]string) {
+	tasks, err := core.DB.GetAllTasks(&db.TaskFilter{OnlyRelevant: true})
+	if err != nil {
+		log.Errorf("event watcher: failed to list tasks: %s", err)
+		return
+	}
+	for _, task := range tasks {
+		id := task.UUID.String()
+		if seen[id] == task.Status {
+			continue
+		}
+		seen[id] = task.Status
+		core.Events.Publish("task."+task.Status, core.tenantOfTarget(task.TargetUUID), task.TargetUUID.String(), task)
+	}
+}
+
+func (core *Core) publishArchiveTransitions(seen map[string]string) {
+	archives, err := core.DB.GetAllArchives(nil)
+	if err != nil {
+		log.Errorf("event watcher: failed to list archives: %s", err)
+		return
+	}
+	for _, archive := range archives {
+		id := archive.UUID.String()
+		prior, known := seen[id]
+		seen[id] = archive.Status
+
+		var topic string
+		switch {
+		case !known:
+			topic = "archive.created"
+		case archive.Status == "expired" && prior != "expired":
+			topic = "archive.expired"
+		default:
+			continue
+		}
+		core.Events.Publish(topic, core.tenantOfTarget(archive.TargetUUID), archive.TargetUUID.String(), archive)
+	}
+}
+
+// seedTaskStatus reads every currently-relevant task's status without
+// publishing anything, so publishTaskTransitions' first tick only fires
+// for tasks that actually change after the watcher starts, instead of
+// treating every pre-existing task as a brand new transition on every
+// process restart.
+func (core *Core) seedTaskStatus() map[string]string {
+	seen := make(map[string]string)
+	tasks, err := core.DB.GetAllTasks(&db.TaskFilter{OnlyRelevant: true})
+	if err != nil {
+		log.Errorf("event watcher: failed to seed task status baseline: %s", err)
+		return seen
+	}
+	for _, task := range tasks {
+		seen[task.UUID.String()] = task.Status
+	}
+	return seen
+}
+
+// seedArchiveStatus is seedTaskStatus's counterpart for
+// publishArchiveTransitions, so already-existing archives don't each
+// fire a false "archive.created" on the first tick after a restart.
+func (core *Core) seedArchiveStatus() map[string]string {
+	seen := make(map[string]string)
+	archives, err := core.DB.GetAllArchives(nil)
+	if err != nil {
+		log.Errorf("event watcher: failed to seed archive status baseline: %s", err)
+		return seen
+	}
+	for _, archive := range archives {
+		seen[archive.UUID.String()] = archive.Status
+	}
+	return seen
+}
+
+// tenantOfTarget resolves target's owning tenant for scoping a
+// published event, returning "" (unscoped) if the target can't be
+// resolved — the event is still published, just not tenant-filtered.
+func (core *Core) tenantOfTarget(target uuid.UUID) string {
+	t, err := core.DB.GetTarget(target)
+	if err != nil || t == nil {
+		return ""
+	}
+	return t.TenantUUID.String()
+}
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"time"
+
+	"github.com/starkandwayne/shield/db"
+)
+
+// watchTasksPollInterval is how often WatchTasks re-checks the DB for
+// task changes. It trades a small amount of staleness for not needing a
+// DB-level notification mechanism (e.g. LISTEN/NOTIFY).
+const watchTasksPollInterval = 2 * time.Second
+
+// WatchTasks returns a channel of *db.Task updates for systemUUID (or for
+// every system, if systemUUID is empty), and a cancel func that must be
+// called to stop the background poller and close the channel. It backs
+// the gRPC CoreService.WatchTasks RPC so operators can tail backup/
+// restore progress without polling GET /v2/system/:uuid themselves.
+func (core *Core) WatchTasks(systemUUID string) (<-chan *db.Task, func()) {
+	out := make(chan *db.Task)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(out)
+
+		seen := make(map[string]string) // task UUID -> status, to detect changes
+		ticker := time.NewTicker(watchTasksPollInterval)
+		defer ticker.Stop()
+
+		filter := &db.TaskFilter{OnlyRelevant: true}
+		if systemUUID != "" {
+			filter.ForTarget = systemUUID
+		}
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				tasks, err := core.DB.GetAllTasks(filter)
+				if err != nil {
+					continue
+				}
+				for _, task := range tasks {
+					id := task.UUID.String()
+					if seen[id] == task.Status {
+						continue
+					}
+					seen[id] = task.Status
+					select {
+					case out <- task:
+					case <-done:
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return out, func() { close(done) }
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/vault/shamir"
+)
+
+// unsealProgress accumulates key shares POSTed to /v2/unlock across
+// multiple calls until the active SealBackend's threshold is reached, at
+// which point Core.Unlock hands the assembled shares to the backend and
+// clears this state, whether or not the unseal succeeds.
+type unsealProgress struct {
+	mu     sync.Mutex
+	shares [][]byte
+}
+
+func (p *unsealProgress) add(share []byte) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.shares = append(p.shares, share)
+	return len(p.shares)
+}
+
+func (p *unsealProgress) reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.shares = nil
+}
+
+// combined reassembles the shares accumulated so far into the secret
+// they were split from. A single submitted share is passed through
+// as-is (the non-Shamir case: a plain local passphrase, or the one
+// share a single-threshold remote backend like Vault/KMS/PKCS11
+// expects); two or more are reconstituted via shamir.Combine, since
+// that's the only thing that correctly undoes shamir.Split.
+func (p *unsealProgress) combined() ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch len(p.shares) {
+	case 0:
+		return nil, fmt.Errorf("no key shares have been submitted")
+	case 1:
+		return p.shares[0], nil
+	default:
+		return shamir.Combine(p.shares)
+	}
+}
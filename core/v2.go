@@ -1,6 +1,7 @@
 package core
 
 import (
+	"encoding/base64"
 	"regexp"
 	"strings"
 
@@ -78,6 +79,35 @@ type v2System struct {
 
 	Jobs  []v2SystemJob  `json:"jobs"`
 	Tasks []v2SystemTask `json:"tasks"`
+
+	// TotalSize is the sum of every valid archive's size for this
+	// system that the background sizing worker has resolved so far; an
+	// archive still awaiting sizing simply doesn't count toward it yet.
+	TotalSize int64 `json:"total_size"`
+}
+
+// v2SystemUsageBucket is one retention policy's contribution to
+// GET /v2/system/:uuid/usage's reported storage consumption.
+type v2SystemUsageBucket struct {
+	Retention string `json:"retention"`
+	Archives  int    `json:"archives"`
+	TotalSize int64  `json:"total_size"`
+}
+
+// v2InitResponse is returned in place of the usual plain-text success
+// message when POST /v2/init seals under a backend that splits its
+// secret into multiple Shamir shares: SHIELD never persists the shares
+// itself, so this is the only time the operator gets to see them.
+type v2InitResponse struct {
+	Shares []string `json:"shares"`
+}
+
+func encodeKeyShares(shares [][]byte) []string {
+	out := make([]string, len(shares))
+	for i, s := range shares {
+		out[i] = base64.StdEncoding.EncodeToString(s)
+	}
+	return out
 }
 
 type v2PatchAnnotation struct {
@@ -103,21 +133,28 @@ func (core *Core) v2API() *route.Router {
 
 	r.Dispatch("POST /v2/init", func(r *route.Request) { // {{{
 		var in struct {
-			Master string `json:"master_password"`
+			Backend string `json:"backend"`
+			Master  string `json:"master_password"`
 		}
 		if !r.Payload(&in) {
 			return
 		}
 
-		/* FIXME: need a better way of doing Missing Parameters */
-		e := MissingParameters()
-		e.Check("master_password", in.Master)
-		if e.IsValid() {
-			r.Fail(route.Bad(e, "%s", e))
-			return
+		// Only the local passphrase backend needs secret material from
+		// the caller; Vault/KMS/PKCS#11 backends are wired up with
+		// everything they need at process startup (WithMasterKeyProvider)
+		// and ignore it.
+		if in.Backend == "" || in.Backend == defaultSealBackend {
+			/* FIXME: need a better way of doing Missing Parameters */
+			e := MissingParameters()
+			e.Check("master_password", in.Master)
+			if e.IsValid() {
+				r.Fail(route.Bad(e, "%s", e))
+				return
+			}
 		}
 
-		init, err := core.Initialize(in.Master)
+		init, shares, err := core.InitializeWithBackend(in.Backend, []byte(in.Master))
 		if err != nil {
 			r.Fail(route.Oops(err, "failed to initialize the SHIELD core"))
 			return
@@ -127,6 +164,10 @@ func (core *Core) v2API() *route.Router {
 			return
 		}
 
+		if len(shares) > 0 {
+			r.OK(&v2InitResponse{Shares: encodeKeyShares(shares)})
+			return
+		}
 		r.Success("Successfully initialzied the SHIELD core")
 	})
 	// }}}
@@ -146,6 +187,11 @@ func (core *Core) v2API() *route.Router {
 			return
 		}
 
+		// For a Shamir-split local backend, each call here submits one
+		// key share; Unlock only actually unseals once enough of them
+		// have come in, returning true in the meantime, so the reply
+		// below is the same for an accepted share as for a completed
+		// unlock.
 		init, err := core.Unlock(in.Master)
 		if err != nil {
 			r.Fail(route.Oops(err, "failed to unlock the SHIELD core"))
@@ -163,6 +209,7 @@ func (core *Core) v2API() *route.Router {
 		var in struct {
 			CurMaster string `json:"current_master_password"`
 			NewMaster string `json:"new_master_password"`
+			ToBackend string `json:"to_backend"`
 		}
 		if !r.Payload(&in) {
 			return
@@ -177,7 +224,7 @@ func (core *Core) v2API() *route.Router {
 			return
 		}
 
-		err := core.Rekey(in.CurMaster, in.NewMaster)
+		err := core.RekeyToBackend([]byte(in.CurMaster), in.ToBackend, []byte(in.NewMaster))
 		if err != nil {
 			r.Fail(route.Oops(err, "failed to rekey the SHIELD core"))
 			return
@@ -215,16 +262,35 @@ func (core *Core) v2API() *route.Router {
 	})
 	// }}}
 
+	r.Dispatch("GET /v2/events", func(r *route.Request) { // {{{
+		core.streamEvents(r, "")
+	})
+	// }}}
+
 	r.Dispatch("GET /v2/systems", func(r *route.Request) { // {{{
-		targets, err := core.DB.GetAllTargets(
-			&db.TargetFilter{
-				SkipUsed:   r.ParamIs("unused", "t"),
-				SkipUnused: r.ParamIs("unused", "f"),
-				SearchName: r.Param("name", ""),
-				ForPlugin:  r.Param("plugin", ""),
-				ExactMatch: r.ParamIs("exact", "t"),
-			},
-		)
+		token, ok := core.authorize(r, db.RoleBackupOperator, db.RoleRestoreOperator, db.RoleTenantAdmin, db.RoleReadOnly)
+		if !ok {
+			return
+		}
+
+		filter := &db.TargetFilter{
+			SkipUsed:   r.ParamIs("unused", "t"),
+			SkipUnused: r.ParamIs("unused", "f"),
+			SearchName: r.Param("name", ""),
+			ForPlugin:  r.Param("plugin", ""),
+			ExactMatch: r.ParamIs("exact", "t"),
+		}
+
+		tenant, err := core.DB.GetTenant(token.TenantUUID)
+		if err != nil {
+			r.Fail(route.Oops(err, "failed to retrieve systems information"))
+			return
+		}
+		if tenant == nil || tenant.Name != db.SystemTenantName {
+			filter.ForTenant = token.TenantUUID.String()
+		}
+
+		targets, err := core.DB.GetAllTargets(filter)
 		if err != nil {
 			r.Fail(route.Oops(err, "failed to retrieve systems information"))
 			return
@@ -243,6 +309,11 @@ func (core *Core) v2API() *route.Router {
 	})
 	// }}}
 	r.Dispatch("GET /v2/system/:uuid", func(r *route.Request) { // {{{
+		token, ok := core.authorize(r, db.RoleBackupOperator, db.RoleRestoreOperator, db.RoleTenantAdmin, db.RoleReadOnly)
+		if !ok {
+			return
+		}
+
 		log.Debugf("%s: got args [%v]", r, r.Args)
 		target, err := core.DB.GetTarget(uuid.Parse(r.Args[1]))
 		if err != nil {
@@ -255,6 +326,14 @@ func (core *Core) v2API() *route.Router {
 			return
 		}
 
+		if owns, err := core.ownsTarget(token, target); err != nil {
+			r.Fail(route.Oops(err, "failed to retrieve system information"))
+			return
+		} else if !owns {
+			r.Fail(route.NotFound(nil, "system %s not found", r.Args[1]))
+			return
+		}
+
 		var system v2System
 		err = core.v2copyTarget(&system, target)
 		if err != nil {
@@ -309,14 +388,80 @@ func (core *Core) v2API() *route.Router {
 					Schedule: archive.Job,
 					Expiry:   (int)((archive.ExpiresAt.Time().Unix() - archive.TakenAt.Time().Unix()) / 86400),
 					Notes:    archive.Notes,
-					Size:     -1, // FIXME
+					Size:     int(archive.SizeBytes),
 				}
 			}
 		}
+		for _, archive := range aa {
+			if archive.SizeBytes > 0 {
+				system.TotalSize += archive.SizeBytes
+			}
+		}
 
 		r.OK(system)
 	})
 	// }}}
+	r.Dispatch("GET /v2/system/:uuid/usage", func(r *route.Request) { // {{{
+		token, ok := core.authorize(r, db.RoleBackupOperator, db.RoleRestoreOperator, db.RoleTenantAdmin, db.RoleReadOnly)
+		if !ok {
+			return
+		}
+
+		target, err := core.DB.GetTarget(uuid.Parse(r.Args[1]))
+		if err != nil {
+			r.Fail(route.Oops(err, "failed to retrieve system usage"))
+			return
+		}
+		if target == nil {
+			r.Fail(route.NotFound(err, "system %s not found", r.Args[1]))
+			return
+		}
+
+		if owns, err := core.ownsTarget(token, target); err != nil {
+			r.Fail(route.Oops(err, "failed to retrieve system usage"))
+			return
+		} else if !owns {
+			r.Fail(route.NotFound(nil, "system %s not found", r.Args[1]))
+			return
+		}
+
+		archives, err := core.DB.GetAllArchives(
+			&db.ArchiveFilter{
+				ForTarget:  target.UUID.String(),
+				WithStatus: []string{"valid"},
+			},
+		)
+		if err != nil {
+			r.Fail(route.Oops(err, "failed to retrieve system usage"))
+			return
+		}
+
+		byRetention := make(map[string]*v2SystemUsageBucket)
+		order := make([]string, 0)
+		for _, archive := range archives {
+			b, ok := byRetention[archive.RetentionName]
+			if !ok {
+				b = &v2SystemUsageBucket{Retention: archive.RetentionName}
+				byRetention[archive.RetentionName] = b
+				order = append(order, archive.RetentionName)
+			}
+			b.Archives++
+			if archive.SizeBytes > 0 {
+				b.TotalSize += archive.SizeBytes
+			}
+		}
+
+		usage := make([]v2SystemUsageBucket, len(order))
+		for i, name := range order {
+			usage[i] = *byRetention[name]
+		}
+		r.OK(usage)
+	})
+	// }}}
+	r.Dispatch("GET /v2/system/:uuid/events", func(r *route.Request) { // {{{
+		core.streamEvents(r, r.Args[1])
+	})
+	// }}}
 	r.Dispatch("POST /v2/systems", func(r *route.Request) { // {{{
 		/* FIXME */
 		r.Fail(route.Errorf(501, nil, "%s: not implemented", r))
@@ -328,6 +473,11 @@ func (core *Core) v2API() *route.Router {
 	})
 	// }}}
 	r.Dispatch("PATCH /v2/system/:uuid", func(r *route.Request) { // {{{
+		token, ok := core.authorize(r, db.RoleBackupOperator, db.RoleRestoreOperator, db.RoleTenantAdmin)
+		if !ok {
+			return
+		}
+
 		var in struct {
 			Annotations []v2PatchAnnotation `json:"annotations"`
 		}
@@ -341,6 +491,14 @@ func (core *Core) v2API() *route.Router {
 			return
 		}
 
+		if owns, err := core.ownsTarget(token, target); err != nil {
+			r.Fail(route.Oops(err, "failed to annotate system %s", r.Args[1]))
+			return
+		} else if !owns {
+			r.Fail(route.NotFound(nil, "system %s not found", r.Args[1]))
+			return
+		}
+
 		for _, ann := range in.Annotations {
 			switch ann.Type {
 			case "task":
@@ -357,6 +515,7 @@ func (core *Core) v2API() *route.Router {
 					r.Fail(route.Oops(err, "failed to annotate task %s", ann.UUID))
 					return
 				}
+				core.Events.Publish("task.annotated", target.TenantUUID.String(), target.UUID.String(), ann)
 
 			case "archive":
 				err = core.DB.AnnotateTargetArchive(
@@ -368,6 +527,7 @@ func (core *Core) v2API() *route.Router {
 					r.Fail(route.Oops(err, "failed to annotate archive %s", ann.UUID))
 					return
 				}
+				core.Events.Publish("archive.annotated", target.TenantUUID.String(), target.UUID.String(), ann)
 
 			default:
 				r.Fail(route.Bad(nil, "unrecognized system annotation type '%s'", ann.Type))
@@ -384,6 +544,10 @@ func (core *Core) v2API() *route.Router {
 	// }}}
 
 	r.Dispatch("GET /v2/agents", func(r *route.Request) { // {{{
+		if _, ok := core.authorize(r, db.RoleTenantAdmin, db.RoleReadOnly); !ok {
+			return
+		}
+
 		agents, err := core.DB.GetAllAgents(nil)
 		if err != nil {
 			r.Fail(route.Oops(err, "failed to retrieve agent information"))
@@ -443,11 +607,16 @@ func (core *Core) v2API() *route.Router {
 			r.Fail(route.Oops(err, "failed to pre-register agent %s at %s:%i", in.Name, peer, in.Port))
 			return
 		}
+		core.Events.Publish("agent.registered", "", "", in)
 		r.Success("pre-registered agent %s at %s:%i", in.Name, peer, in.Port)
 	})
 	// }}}
 
 	r.Dispatch("GET /v2/tenants", func(r *route.Request) { // {{{
+		if _, ok := core.authorize(r, db.RoleTenantAdmin); !ok {
+			return
+		}
+
 		tenants, err := core.DB.GetAllTenants()
 		if err != nil {
 			r.Fail(route.Oops(err, "failed to retrieve tenants information"))
@@ -457,6 +626,10 @@ func (core *Core) v2API() *route.Router {
 	})
 	// }}}
 	r.Dispatch("POST /v2/tenants", func(r *route.Request) { // {{{
+		if _, ok := core.authorize(r, db.RoleTenantAdmin); !ok {
+			return
+		}
+
 		var in struct {
 			UUID string `json:"uuid"`
 			Name string `json:"name"`
@@ -487,6 +660,10 @@ func (core *Core) v2API() *route.Router {
 	})
 	// }}}
 	r.Dispatch("PUT /v2/tenant/:uuid", func(r *route.Request) { // {{{
+		if _, ok := core.authorize(r, db.RoleTenantAdmin); !ok {
+			return
+		}
+
 		var in struct {
 			UUID string `json:"uuid"`
 			Name string `json:"name"`
@@ -512,6 +689,93 @@ func (core *Core) v2API() *route.Router {
 		r.OK(t)
 	})
 	// }}}
+	r.Dispatch("POST /v2/tenant/:uuid/tokens", func(r *route.Request) { // {{{
+		caller, ok := core.authorize(r, db.RoleTenantAdmin)
+		if !ok {
+			return
+		}
+
+		var in struct {
+			Name string `json:"name"`
+			Role string `json:"role"`
+		}
+		if !r.Payload(&in) {
+			return
+		}
+
+		e := MissingParameters()
+		e.Check("name", in.Name)
+		e.Check("role", in.Role)
+		if e.IsValid() {
+			r.Fail(route.Bad(e, "%s", e))
+			return
+		}
+
+		roles, err := core.DB.GetAllRoles()
+		if err != nil {
+			r.Fail(route.Oops(err, "failed to validate role '%s'", in.Role))
+			return
+		}
+		valid := false
+		for _, role := range roles {
+			if role.Name == in.Role {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			r.Fail(route.Bad(nil, "unrecognized role '%s'", in.Role))
+			return
+		}
+
+		tenant, err := core.DB.GetTenant(uuid.Parse(r.Args[1]))
+		if err != nil {
+			r.Fail(route.Oops(err, "failed to resolve tenant %s", r.Args[1]))
+			return
+		}
+		if tenant == nil {
+			r.Fail(route.NotFound(nil, "tenant %s not found", r.Args[1]))
+			return
+		}
+		// authorizeToken with no roles passes only system-tenant tokens.
+		if !uuid.Equal(tenant.UUID, caller.TenantUUID) {
+			if system, err := core.authorizeToken(caller); err != nil {
+				r.Fail(route.Oops(err, "failed to resolve tenant %s", r.Args[1]))
+				return
+			} else if !system {
+				r.Fail(route.NotFound(nil, "tenant %s not found", r.Args[1]))
+				return
+			}
+		}
+
+		// Granting a role to a tenant (if it isn't already bound) is
+		// implicit in minting the tenant's first token under that role;
+		// GetRoleBindingsForTenant then reflects every role the tenant
+		// has ever had a token minted for.
+		if _, err := core.DB.EnsureRoleBinding(tenant.UUID, in.Role); err != nil {
+			r.Fail(route.Oops(err, "failed to grant role '%s' to tenant '%s'", in.Role, tenant.Name))
+			return
+		}
+
+		token, secret, err := core.DB.CreateAPIToken(tenant.UUID, in.Role, in.Name)
+		if err != nil {
+			r.Fail(route.Oops(err, "failed to mint API token for tenant '%s'", tenant.Name))
+			return
+		}
+
+		r.OK(struct {
+			UUID  uuid.UUID `json:"uuid"`
+			Name  string    `json:"name"`
+			Role  string    `json:"role"`
+			Token string    `json:"token"`
+		}{
+			UUID:  token.UUID,
+			Name:  token.Name,
+			Role:  token.Role,
+			Token: secret,
+		})
+	})
+	// }}}
 	r.Dispatch("PATCH /v2/tenant/:uuid", func(r *route.Request) { // {{{
 		/* FIXME */
 		r.Fail(route.Errorf(501, nil, "%s: not implemented", r))
@@ -524,4 +788,4 @@ func (core *Core) v2API() *route.Router {
 	// }}}
 
 	return r
-}
\ No newline at end of file
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/pborman/uuid"
+)
+
+// AgentFilter narrows GetAllAgents to the subset of agents a caller is
+// interested in. A nil filter (or zero value) matches every agent.
+type AgentFilter struct{}
+
+// GetAllAgents returns every registered (or pre-registered) agent.
+func (db *DB) GetAllAgents(filter *AgentFilter) ([]*Agent, error) {
+	rows, err := db.Query(`SELECT uuid, name, address, version FROM agents ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	agents := make([]*Agent, 0)
+	for rows.Next() {
+		a := &Agent{}
+		var id string
+		if err := rows.Scan(&id, &a.Name, &a.Address, &a.Version); err != nil {
+			return nil, err
+		}
+		a.UUID = uuid.Parse(id)
+		agents = append(agents, a)
+	}
+	return agents, nil
+}
+
+// PreRegisterAgent records that an agent named name is expected to show
+// up and register itself from peer:port, ahead of its first heartbeat.
+func (db *DB) PreRegisterAgent(peer, name string, port int) error {
+	return db.Exec(
+		`INSERT INTO agents (uuid, name, address, version)
+		      VALUES ($1, $2, $3, '')
+		 ON CONFLICT (address) DO UPDATE SET name = $2`,
+		uuid.NewRandom().String(), name, fmt.Sprintf("%s:%d", peer, port),
+	)
+}
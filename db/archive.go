@@ -0,0 +1,104 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pborman/uuid"
+)
+
+// GetAllArchives returns every archive matching filter.
+func (db *DB) GetAllArchives(filter *ArchiveFilter) ([]*Archive, error) {
+	wheres := []string{"1=1"}
+	args := []interface{}{}
+
+	if filter != nil {
+		if filter.ForTarget != "" {
+			wheres = append(wheres, fmt.Sprintf("target_uuid = $%d", len(args)+1))
+			args = append(args, filter.ForTarget)
+		}
+		if len(filter.WithStatus) > 0 {
+			placeholders := make([]string, len(filter.WithStatus))
+			for i, s := range filter.WithStatus {
+				placeholders[i] = fmt.Sprintf("$%d", len(args)+1)
+				args = append(args, s)
+			}
+			wheres = append(wheres, fmt.Sprintf("status IN (%s)", strings.Join(placeholders, ", ")))
+		}
+	}
+
+	rows, err := db.Query(
+		fmt.Sprintf(`SELECT uuid, target_uuid, job, status, notes, taken_at, expires_at,
+		                    store_uuid, store_plugin, store_endpoint, retention_name, size_bytes
+		               FROM archives WHERE %s ORDER BY taken_at DESC`,
+			strings.Join(wheres, " AND ")),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	archives := make([]*Archive, 0)
+	for rows.Next() {
+		a := &Archive{}
+		var id, targetID, storeID string
+		if err := rows.Scan(&id, &targetID, &a.Job, &a.Status, &a.Notes, &a.TakenAt, &a.ExpiresAt,
+			&storeID, &a.StorePlugin, &a.StoreEndpoint, &a.RetentionName, &a.SizeBytes); err != nil {
+			return nil, err
+		}
+		a.UUID = uuid.Parse(id)
+		a.TargetUUID = uuid.Parse(targetID)
+		a.StoreUUID = uuid.Parse(storeID)
+		archives = append(archives, a)
+	}
+	return archives, nil
+}
+
+// AnnotateTargetArchive sets the operator-supplied notes on the archive
+// identified by archiveUUID, scoped to target.
+func (db *DB) AnnotateTargetArchive(target uuid.UUID, archiveUUID, notes string) error {
+	return db.Exec(
+		`UPDATE archives SET notes = $1 WHERE uuid = $2 AND target_uuid = $3`,
+		notes, archiveUUID, target.String(),
+	)
+}
+
+// unknownArchiveSize is the sentinel SizeBytes value for archives the
+// sizing worker hasn't stat'd yet.
+const unknownArchiveSize = -1
+
+// GetArchivesWithUnknownSize returns up to limit archives whose size
+// hasn't been resolved yet, for the background sizing worker to stat.
+func (db *DB) GetArchivesWithUnknownSize(limit int) ([]*Archive, error) {
+	rows, err := db.Query(
+		`SELECT uuid, target_uuid, store_uuid, store_plugin, store_endpoint
+		   FROM archives WHERE size_bytes = $1 AND status = 'valid'
+		  ORDER BY taken_at ASC LIMIT $2`,
+		unknownArchiveSize, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	archives := make([]*Archive, 0)
+	for rows.Next() {
+		a := &Archive{SizeBytes: unknownArchiveSize}
+		var id, targetID, storeID string
+		if err := rows.Scan(&id, &targetID, &storeID, &a.StorePlugin, &a.StoreEndpoint); err != nil {
+			return nil, err
+		}
+		a.UUID = uuid.Parse(id)
+		a.TargetUUID = uuid.Parse(targetID)
+		a.StoreUUID = uuid.Parse(storeID)
+		archives = append(archives, a)
+	}
+	return archives, nil
+}
+
+// SetArchiveSize records the size, in bytes, the sizing worker observed
+// for the archive identified by id.
+func (db *DB) SetArchiveSize(id uuid.UUID, sizeBytes int64) error {
+	return db.Exec(`UPDATE archives SET size_bytes = $1 WHERE uuid = $2`, sizeBytes, id.String())
+}
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"github.com/pborman/uuid"
+)
+
+// The fixed set of roles a RoleBinding can grant within a tenant. Roles
+// are deliberately not user-definable (yet): every v2 handler's
+// authorization check is written against one of these four names.
+const (
+	RoleBackupOperator  = "backup-operator"
+	RoleRestoreOperator = "restore-operator"
+	RoleTenantAdmin     = "tenant-admin"
+	RoleReadOnly        = "read-only"
+)
+
+// SystemTenantName is the reserved tenant whose bindings can see and act
+// on tasks/systems across every other tenant. POST /v2/tenants refuses
+// to create a tenant with this name.
+const SystemTenantName = "system"
+
+// Role is one of the fixed RoleBackup*/RoleTenantAdmin/RoleReadOnly
+// names; it exists as a row (rather than just the constants above) so
+// RoleBinding can foreign-key to it.
+type Role struct {
+	UUID uuid.UUID `json:"uuid"`
+	Name string    `json:"name"`
+}
+
+// RoleBinding records that Role has been granted within Tenant; one is
+// ensured each time a token is minted under that role. Authorization
+// checks a token's own Role, not the tenant's bindings.
+type RoleBinding struct {
+	UUID       uuid.UUID `json:"uuid"`
+	TenantUUID uuid.UUID `json:"tenant_uuid"`
+	RoleUUID   uuid.UUID `json:"role_uuid"`
+	Role       string    `json:"role"`
+}
+
+// GetAllRoles returns the fixed set of roles known to this SHIELD core.
+func (db *DB) GetAllRoles() ([]*Role, error) {
+	rows, err := db.Query(`SELECT uuid, name FROM roles ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := make([]*Role, 0)
+	for rows.Next() {
+		r := &Role{}
+		var id string
+		if err := rows.Scan(&id, &r.Name); err != nil {
+			return nil, err
+		}
+		r.UUID = uuid.Parse(id)
+		roles = append(roles, r)
+	}
+	return roles, nil
+}
+
+// CreateRoleBinding grants role to tenant, returning the new binding.
+func (db *DB) CreateRoleBinding(tenant uuid.UUID, role string) (*RoleBinding, error) {
+	id := uuid.NewRandom()
+	rb := &RoleBinding{
+		UUID:       id,
+		TenantUUID: tenant,
+		Role:       role,
+	}
+	return rb, db.Exec(
+		`INSERT INTO role_bindings (uuid, tenant_uuid, role) VALUES ($1, $2, $3)`,
+		id.String(), tenant.String(), role,
+	)
+}
+
+// EnsureRoleBinding grants role to tenant if it isn't already bound,
+// returning the existing or newly-created binding. POST
+// /v2/tenant/:uuid/tokens calls this before minting a token, so a
+// tenant's granted roles (visible via GetRoleBindingsForTenant) always
+// reflect every role a token has ever been minted under.
+func (db *DB) EnsureRoleBinding(tenant uuid.UUID, role string) (*RoleBinding, error) {
+	existing, err := db.GetRoleBindingsForTenant(tenant)
+	if err != nil {
+		return nil, err
+	}
+	for _, rb := range existing {
+		if rb.Role == role {
+			return rb, nil
+		}
+	}
+	return db.CreateRoleBinding(tenant, role)
+}
+
+// GetRoleBindingsForTenant returns every role granted within tenant.
+func (db *DB) GetRoleBindingsForTenant(tenant uuid.UUID) ([]*RoleBinding, error) {
+	rows, err := db.Query(
+		`SELECT uuid, tenant_uuid, role FROM role_bindings WHERE tenant_uuid = $1`,
+		tenant.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bindings := make([]*RoleBinding, 0)
+	for rows.Next() {
+		rb := &RoleBinding{}
+		var id, tenantID string
+		if err := rows.Scan(&id, &tenantID, &rb.Role); err != nil {
+			return nil, err
+		}
+		rb.UUID = uuid.Parse(id)
+		rb.TenantUUID = uuid.Parse(tenantID)
+		bindings = append(bindings, rb)
+	}
+	return bindings, nil
+}
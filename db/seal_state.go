@@ -0,0 +1,33 @@
+package db
+
+// SealState is the single persisted row describing how this SHIELD
+// core's data encryption key is currently wrapped: which SealBackend
+// sealed it, and the wrapped key material itself.
+type SealState struct {
+	Backend string
+	Wrapped []byte
+}
+
+// GetSealState returns the current seal state, or (nil, nil) if this
+// core has never been initialized.
+func (db *DB) GetSealState() (*SealState, error) {
+	r := db.QueryRow(`SELECT backend, wrapped_key FROM seal_state WHERE id = 1`)
+
+	s := &SealState{}
+	if err := r.Scan(&s.Backend, &s.Wrapped); err != nil {
+		if err == ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetSealState persists the (possibly new) backend and wrapped key, e.g.
+// after POST /v2/init or a successful POST /v2/rekey migration.
+func (db *DB) SetSealState(s *SealState) error {
+	return db.Exec(`
+		INSERT INTO seal_state (id, backend, wrapped_key) VALUES (1, $1, $2)
+		ON CONFLICT (id) DO UPDATE SET backend = $1, wrapped_key = $2
+	`, s.Backend, s.Wrapped)
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pborman/uuid"
+)
+
+// GetAllTargets returns every target matching filter's name, plugin and
+// tenant fields; SkipUsed and SkipUnused are not applied.
+func (db *DB) GetAllTargets(filter *TargetFilter) ([]*Target, error) {
+	wheres := []string{"1=1"}
+	args := []interface{}{}
+
+	if filter != nil {
+		if filter.SearchName != "" {
+			if filter.ExactMatch {
+				wheres = append(wheres, fmt.Sprintf("name = $%d", len(args)+1))
+				args = append(args, filter.SearchName)
+			} else {
+				wheres = append(wheres, fmt.Sprintf("name ILIKE $%d", len(args)+1))
+				args = append(args, "%"+filter.SearchName+"%")
+			}
+		}
+		if filter.ForPlugin != "" {
+			wheres = append(wheres, fmt.Sprintf("plugin = $%d", len(args)+1))
+			args = append(args, filter.ForPlugin)
+		}
+		if filter.ForTenant != "" {
+			wheres = append(wheres, fmt.Sprintf("tenant_uuid = $%d", len(args)+1))
+			args = append(args, filter.ForTenant)
+		}
+	}
+
+	rows, err := db.Query(
+		fmt.Sprintf(`SELECT uuid, name, summary, plugin, tenant_uuid FROM targets WHERE %s ORDER BY name`,
+			strings.Join(wheres, " AND ")),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	targets := make([]*Target, 0)
+	for rows.Next() {
+		t := &Target{}
+		var id, tenantID string
+		if err := rows.Scan(&id, &t.Name, &t.Summary, &t.Plugin, &tenantID); err != nil {
+			return nil, err
+		}
+		t.UUID = uuid.Parse(id)
+		t.TenantUUID = uuid.Parse(tenantID)
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// GetTarget returns the target identified by id, or (nil, nil) if no
+// such target exists.
+func (db *DB) GetTarget(id uuid.UUID) (*Target, error) {
+	r := db.QueryRow(`SELECT uuid, name, summary, plugin, tenant_uuid FROM targets WHERE uuid = $1`, id.String())
+
+	t := &Target{}
+	var uid, tenantID string
+	if err := r.Scan(&uid, &t.Name, &t.Summary, &t.Plugin, &tenantID); err != nil {
+		if err == ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	t.UUID = uuid.Parse(uid)
+	t.TenantUUID = uuid.Parse(tenantID)
+	return t, nil
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pborman/uuid"
+)
+
+// GetAllTasks returns every task matching filter. When filter.OnlyRelevant
+// is set, tasks already marked irrelevant by MarkTasksIrrelevant are
+// excluded.
+func (db *DB) GetAllTasks(filter *TaskFilter) ([]*Task, error) {
+	wheres := []string{"1=1"}
+	args := []interface{}{}
+
+	if filter != nil {
+		if filter.ForTarget != "" {
+			wheres = append(wheres, fmt.Sprintf("target_uuid = $%d", len(args)+1))
+			args = append(args, filter.ForTarget)
+		}
+		if filter.OnlyRelevant {
+			wheres = append(wheres, "NOT irrelevant")
+		}
+	}
+
+	rows, err := db.Query(
+		fmt.Sprintf(`SELECT uuid, op, status, owner, ok, notes, started_at, target_uuid, archive_uuid FROM tasks WHERE %s ORDER BY started_at DESC`,
+			strings.Join(wheres, " AND ")),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make([]*Task, 0)
+	for rows.Next() {
+		t := &Task{}
+		var id, targetID, archiveID string
+		if err := rows.Scan(&id, &t.Op, &t.Status, &t.Owner, &t.OK, &t.Notes, &t.StartedAt, &targetID, &archiveID); err != nil {
+			return nil, err
+		}
+		t.UUID = uuid.Parse(id)
+		t.TargetUUID = uuid.Parse(targetID)
+		t.ArchiveUUID = uuid.Parse(archiveID)
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+// AnnotateTargetTask applies ann to the task identified by taskUUID,
+// scoped to target: a non-empty Disposition replaces the task's status,
+// and Notes replaces its notes. Clear is not applied.
+func (db *DB) AnnotateTargetTask(target uuid.UUID, taskUUID string, ann *TaskAnnotation) error {
+	return db.Exec(
+		`UPDATE tasks SET status = COALESCE(NULLIF($1, ''), status), notes = $2
+		   WHERE uuid = $3 AND target_uuid = $4`,
+		ann.Disposition, ann.Notes, taskUUID, target.String(),
+	)
+}
+
+// MarkTasksIrrelevant flags completed tasks that have been superseded by
+// a newer task of the same op against the same target as irrelevant, so
+// GetAllTasks with OnlyRelevant set stops returning them.
+func (db *DB) MarkTasksIrrelevant() error {
+	return db.Exec(`
+		UPDATE tasks SET irrelevant = true
+		 WHERE status = 'done'
+		   AND uuid NOT IN (
+		       SELECT DISTINCT ON (target_uuid, op) uuid
+		         FROM tasks
+		        ORDER BY target_uuid, op, started_at DESC
+		   )
+	`)
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"github.com/pborman/uuid"
+)
+
+// GetAllTenants returns every tenant known to this SHIELD core.
+func (db *DB) GetAllTenants() ([]*Tenant, error) {
+	rows, err := db.Query(`SELECT uuid, name FROM tenants ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenants := make([]*Tenant, 0)
+	for rows.Next() {
+		t := &Tenant{}
+		var id string
+		if err := rows.Scan(&id, &t.Name); err != nil {
+			return nil, err
+		}
+		t.UUID = uuid.Parse(id)
+		tenants = append(tenants, t)
+	}
+	return tenants, nil
+}
+
+// GetTenant returns the tenant identified by id, or (nil, nil) if no such
+// tenant exists.
+func (db *DB) GetTenant(id uuid.UUID) (*Tenant, error) {
+	r := db.QueryRow(`SELECT uuid, name FROM tenants WHERE uuid = $1`, id.String())
+
+	t := &Tenant{}
+	var uid string
+	if err := r.Scan(&uid, &t.Name); err != nil {
+		if err == ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	t.UUID = uuid.Parse(uid)
+	return t, nil
+}
+
+// CreateTenant creates a new tenant named name. If id is non-empty it's
+// used as the tenant's UUID (letting callers pre-seed well-known tenant
+// IDs); otherwise one is generated.
+func (db *DB) CreateTenant(id, name string) (*Tenant, error) {
+	tid := uuid.Parse(id)
+	if tid == nil {
+		tid = uuid.NewRandom()
+	}
+
+	t := &Tenant{UUID: tid, Name: name}
+	return t, db.Exec(`INSERT INTO tenants (uuid, name) VALUES ($1, $2)`, t.UUID.String(), t.Name)
+}
+
+// UpdateTenant renames the tenant identified by id.
+func (db *DB) UpdateTenant(id, name string) (*Tenant, error) {
+	if err := db.Exec(`UPDATE tenants SET name = $1 WHERE uuid = $2`, name, id); err != nil {
+		return nil, err
+	}
+	return db.GetTenant(uuid.Parse(id))
+}
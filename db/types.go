@@ -0,0 +1,131 @@
+// Package db is the persistence layer for SHIELD core: targets (aka
+// "systems"), the jobs/schedules that back them up, the tasks that run
+// those jobs, the archives tasks produce, the agents that execute them,
+// and (as of the multi-tenant work) tenants, roles and API tokens.
+package db
+
+import (
+	"database/sql"
+
+	"github.com/pborman/uuid"
+	"github.com/starkandwayne/goutils/timestamp"
+)
+
+// ErrNoRows is sql.ErrNoRows, what a single-row Scan returns on a miss.
+// The single-row lookups (GetTenant, GetTarget, ...) check for it and
+// return (nil, nil) instead, so their callers check for a nil result.
+var ErrNoRows = sql.ErrNoRows
+
+// DB wraps the underlying SQL connection pool with the query/exec helpers
+// the rest of this package's CRUD methods are built on.
+type DB struct {
+	conn *sql.DB
+}
+
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.conn.Query(query, args...)
+}
+
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.conn.QueryRow(query, args...)
+}
+
+func (db *DB) Exec(query string, args ...interface{}) error {
+	_, err := db.conn.Exec(query, args...)
+	return err
+}
+
+// Target is a registered backup/restore endpoint — what the v2 API calls
+// a "system".
+type Target struct {
+	UUID       uuid.UUID `json:"uuid"`
+	Name       string    `json:"name"`
+	Summary    string    `json:"summary"`
+	Plugin     string    `json:"plugin"`
+	TenantUUID uuid.UUID `json:"tenant_uuid"`
+}
+
+// TargetFilter narrows GetAllTargets to the subset of targets a caller is
+// interested in.
+type TargetFilter struct {
+	SkipUsed   bool
+	SkipUnused bool
+	SearchName string
+	ForPlugin  string
+	ExactMatch bool
+	ForTenant  string
+}
+
+// Archive is a single completed backup, stored by whatever plugin the
+// target's job configured.
+type Archive struct {
+	UUID       uuid.UUID           `json:"uuid"`
+	TargetUUID uuid.UUID           `json:"target_uuid"`
+	Job        string              `json:"job"`
+	Status     string              `json:"status"`
+	Notes      string              `json:"notes"`
+	TakenAt    timestamp.Timestamp `json:"taken_at"`
+	ExpiresAt  timestamp.Timestamp `json:"expires_at"`
+
+	StoreUUID     uuid.UUID `json:"store_uuid"`
+	StorePlugin   string    `json:"store_plugin"`
+	StoreEndpoint string    `json:"-"`
+	RetentionName string    `json:"retention_name"`
+
+	// SizeBytes is -1 until the background sizing worker (see
+	// core.sizer.go) has stat'd this archive against its store.
+	SizeBytes int64 `json:"size_bytes"`
+}
+
+// ArchiveFilter narrows GetAllArchives to the subset of archives a caller
+// is interested in.
+type ArchiveFilter struct {
+	ForTarget  string
+	WithStatus []string
+}
+
+// Task is a single scheduled or ad-hoc run of a job against a target.
+type Task struct {
+	UUID        uuid.UUID           `json:"uuid"`
+	Op          string              `json:"op"`
+	Status      string              `json:"status"`
+	Owner       string              `json:"owner"`
+	OK          bool                `json:"ok"`
+	Notes       string              `json:"notes"`
+	StartedAt   timestamp.Timestamp `json:"started_at"`
+	TargetUUID  uuid.UUID           `json:"target_uuid"`
+	ArchiveUUID uuid.UUID           `json:"archive_uuid"`
+}
+
+// TaskFilter narrows GetAllTasks to the subset of tasks a caller is
+// interested in.
+type TaskFilter struct {
+	ForTarget    string
+	OnlyRelevant bool
+}
+
+// TaskAnnotation carries the operator-supplied disposition/notes/clear
+// fields of a PATCH /v2/system/:uuid "task" annotation. See
+// AnnotateTargetTask for which of them are applied.
+type TaskAnnotation struct {
+	Disposition string
+	Notes       string
+	Clear       string
+}
+
+// Agent is a registered (or pre-registered) shield-agent instance.
+type Agent struct {
+	UUID    uuid.UUID `json:"uuid"`
+	Name    string    `json:"name"`
+	Address string    `json:"address"`
+	Version string    `json:"version"`
+}
+
+// Tenant is a billing/ownership boundary: systems, archives and tasks
+// all belong to exactly one tenant, except for the reserved
+// SystemTenantName tenant, whose role bindings can see across all of
+// them.
+type Tenant struct {
+	UUID uuid.UUID `json:"uuid"`
+	Name string    `json:"name"`
+}
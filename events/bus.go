@@ -0,0 +1,173 @@
+// Package events implements SHIELD's in-memory event bus: the
+// publish/subscribe mechanism behind GET /v2/events and
+// GET /v2/system/:uuid/events, the Server-Sent Events streams that
+// notify callers as tasks change status, archives are annotated, and
+// agents pre-register.
+package events
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/starkandwayne/goutils/timestamp"
+)
+
+// bufferSize is how many of the most recently published events Bus keeps
+// around, so Replay can serve what a reconnecting SSE client (via
+// Last-Event-ID) missed while it was disconnected.
+const bufferSize = 1000
+
+// Event is a single notification published to a Bus.
+type Event struct {
+	ID     uint64              `json:"id"`
+	Topic  string              `json:"topic"`
+	Tenant string              `json:"tenant,omitempty"`
+	Target string              `json:"target,omitempty"`
+	At     timestamp.Timestamp `json:"at"`
+	Data   interface{}         `json:"data"`
+}
+
+// Bus is SHIELD's in-memory event bus. Publish fans a new Event out to
+// every live Subscription whose patterns match it and appends it to a
+// ring buffer; Replay serves that ring buffer to subscribers catching up
+// after a reconnect.
+type Bus struct {
+	lock        sync.Mutex
+	nextID      uint64
+	ring        []Event
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBus constructs an empty Bus, ready for Publish and Subscribe.
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription is a live listener registered via Bus.Subscribe. Events
+// matching one of Patterns arrive on C until Close is called.
+type Subscription struct {
+	Patterns []string
+	C        chan Event
+
+	bus *Bus
+}
+
+// Close unregisters sub from its Bus and closes C. It is safe to call
+// more than once.
+func (sub *Subscription) Close() {
+	sub.bus.lock.Lock()
+	defer sub.bus.lock.Unlock()
+
+	if _, ok := sub.bus.subscribers[sub]; ok {
+		delete(sub.bus.subscribers, sub)
+		close(sub.C)
+	}
+}
+
+// Subscribe registers a new Subscription that receives every future
+// event matching one of patterns (see Match for the matching rules).
+// Callers must Close the Subscription once they stop listening.
+func (bus *Bus) Subscribe(patterns ...string) *Subscription {
+	sub := &Subscription{
+		Patterns: patterns,
+		C:        make(chan Event, 64),
+		bus:      bus,
+	}
+
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	bus.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Publish records a new event under topic (e.g. "task.annotated"),
+// scoped to tenant and target (either may be blank, for events that
+// aren't tenant- or system-scoped, like an agent pre-registering), and
+// fans it out to every live Subscription whose patterns match it.
+//
+// A subscriber whose channel is full is skipped rather than blocking
+// Publish; an SSE handler that falls behind is expected to reconnect and
+// replay from the ring buffer instead.
+func (bus *Bus) Publish(topic, tenant, target string, data interface{}) Event {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+
+	bus.nextID++
+	ev := Event{
+		ID:     bus.nextID,
+		Topic:  topic,
+		Tenant: tenant,
+		Target: target,
+		At:     timestamp.Now(),
+		Data:   data,
+	}
+
+	bus.ring = append(bus.ring, ev)
+	if len(bus.ring) > bufferSize {
+		bus.ring = bus.ring[len(bus.ring)-bufferSize:]
+	}
+
+	for sub := range bus.subscribers {
+		if !matchAny(sub.Patterns, ev) {
+			continue
+		}
+		select {
+		case sub.C <- ev:
+		default:
+		}
+	}
+
+	return ev
+}
+
+// Replay returns every event published since lastID (exclusive) that
+// matches one of patterns, oldest first. Events older than the ring
+// buffer's retention are silently omitted.
+func (bus *Bus) Replay(lastID uint64, patterns ...string) []Event {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+
+	out := make([]Event, 0)
+	for _, ev := range bus.ring {
+		if ev.ID <= lastID {
+			continue
+		}
+		if !matchAny(patterns, ev) {
+			continue
+		}
+		out = append(out, ev)
+	}
+	return out
+}
+
+func matchAny(patterns []string, ev Event) bool {
+	for _, p := range patterns {
+		if Match(p, ev) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether ev satisfies pattern. Two forms are recognized:
+//
+//   - "<kind>.*" (e.g. "task.*", "archive.*", "agent.*") matches any
+//     event whose Topic begins with "<kind>.".
+//   - "tenant.<uuid>.*" matches any event whose Tenant equals <uuid>,
+//     regardless of Topic.
+//
+// Anything else is compared to ev.Topic for an exact match.
+func Match(pattern string, ev Event) bool {
+	if pattern == ev.Topic {
+		return true
+	}
+	if rest := strings.TrimPrefix(pattern, "tenant."); rest != pattern {
+		return ev.Tenant != "" && ev.Tenant == strings.TrimSuffix(rest, ".*")
+	}
+	if prefix := strings.TrimSuffix(pattern, "*"); prefix != pattern {
+		return strings.HasPrefix(ev.Topic, prefix) && len(ev.Topic) > len(prefix)
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package grpc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// ContentSubtype is the gRPC content-subtype clients must select (via
+// grpc.CallContentSubtype or grpc.WithDefaultCallOptions) to have their
+// CoreService calls marshaled with jsonCodec instead of grpc-go's
+// default protobuf codec.
+const ContentSubtype = "shieldjson"
+
+// jsonCodec marshals gRPC messages as JSON instead of protobuf wire
+// format. shieldpb's message types are hand-maintained Go structs, not
+// real protoreflect.Message implementations (see shieldpb's package
+// comment), so they can't go through the protobuf wire codec grpc-go
+// registers by default under the "proto" name. Registering this codec
+// under its own name (ContentSubtype) rather than "proto" means it only
+// applies to calls that opt into it, leaving any other real-protobuf
+// gRPC traffic in this binary on the default codec.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %T: %s", v, err)
+	}
+	return b, nil
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %T: %s", v, err)
+	}
+	return nil
+}
+
+func (jsonCodec) Name() string { return ContentSubtype }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
@@ -0,0 +1,164 @@
+package grpc
+
+import (
+	"context"
+	"regexp"
+	"runtime/debug"
+	"strings"
+
+	"github.com/starkandwayne/goutils/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/starkandwayne/shield/core"
+	"github.com/starkandwayne/shield/db"
+)
+
+// apiTokenKey is the context key authUnaryInterceptor/authStreamInterceptor
+// stash the call's validated *db.APIToken under, for handlers that need
+// to scope their work to the caller's tenant (mirroring how the HTTP v2
+// API's authorize hands its token back to the dispatch func directly).
+type apiTokenKey struct{}
+
+// APITokenFromContext returns the *db.APIToken validateSession resolved
+// for this call, or (nil, false) if the call was never authenticated
+// (e.g. the Health RPC, which skips auth entirely).
+func APITokenFromContext(ctx context.Context) (*db.APIToken, bool) {
+	t, ok := ctx.Value(apiTokenKey{}).(*db.APIToken)
+	return t, ok
+}
+
+type peerAddrKey struct{}
+
+// peerAddressFromContext extracts the remote address gRPC negotiated for
+// this call, mirroring the `r.Req.RemoteAddr` peer-sniffing the HTTP v2
+// POST /v2/agents handler does.
+func peerAddressFromContext(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "", false
+	}
+	return regexp.MustCompile(`:\d+$`).ReplaceAllString(p.Addr.String(), ""), true
+}
+
+// recoveryUnaryInterceptor turns a panic in a unary handler into a
+// codes.Internal error, logging the stack trace, so a crash in one
+// handler doesn't take down the shared listener.
+func recoveryUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorf("panic handling %s: %v\n%s", info.FullMethod, r, debug.Stack())
+				err = status.Errorf(codes.Internal, "internal error handling %s", info.FullMethod)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// recoveryStreamInterceptor is the streaming-RPC equivalent of
+// recoveryUnaryInterceptor, needed because WatchTasks runs for the
+// lifetime of the client connection.
+func recoveryStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorf("panic handling %s: %v\n%s", info.FullMethod, r, debug.Stack())
+				err = status.Errorf(codes.Internal, "internal error handling %s", info.FullMethod)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// authUnaryInterceptor resolves the bearer token carried in the call's
+// "authorization" metadata to the scoped API token it was minted as (see
+// validateSession), rejecting the call before it reaches a handler if the
+// token doesn't check out. Session cookies and c's configured auth
+// providers are not consulted. The Health RPC skips this check.
+func authUnaryInterceptor(c *core.Core) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if info.FullMethod == "/shield.CoreService/Health" {
+			return handler(ctx, req)
+		}
+		token, err := validateSession(c, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(context.WithValue(ctx, apiTokenKey{}, token), req)
+	}
+}
+
+func authStreamInterceptor(c *core.Core) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		token, err := validateSession(c, ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &tokenStream{ServerStream: ss, ctx: context.WithValue(ss.Context(), apiTokenKey{}, token)})
+	}
+}
+
+// tokenStream overrides ServerStream.Context to carry the validated
+// *db.APIToken, the streaming-RPC equivalent of the context.WithValue
+// wrapping authUnaryInterceptor does for unary calls.
+type tokenStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tokenStream) Context() context.Context { return s.ctx }
+
+// validateSession resolves the bearer token carried in ctx's incoming
+// metadata to the db.APIToken it was minted as — the same tenant-scoped
+// tokens POST /v2/tenant/:uuid/tokens mints for the HTTP v2 API, so
+// there's no separate login RPC for this transport.
+func validateSession(c *core.Core, ctx context.Context) (*db.APIToken, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing authentication metadata")
+	}
+	tokens := md.Get("authorization")
+	if len(tokens) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization token")
+	}
+	bearer := strings.TrimPrefix(tokens[0], "Bearer ")
+	token, err := c.ValidateSessionToken(bearer)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid session: %s", err)
+	}
+	return token, nil
+}
+
+// metricsUnaryInterceptor records a per-tenant call counter so operators
+// can see which tenants are driving gRPC load, the streaming analogue of
+// the request metrics the HTTP listener already tracks.
+func metricsUnaryInterceptor(c *core.Core) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		tenant := tenantFromContext(ctx)
+		c.CountAPICall(tenant, info.FullMethod)
+		return handler(ctx, req)
+	}
+}
+
+func metricsStreamInterceptor(c *core.Core) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		tenant := tenantFromContext(ss.Context())
+		c.CountAPICall(tenant, info.FullMethod)
+		return handler(srv, ss)
+	}
+}
+
+func tenantFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if v := md.Get("x-shield-tenant"); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
@@ -0,0 +1,40 @@
+package grpc
+
+import (
+	"net"
+
+	"github.com/starkandwayne/goutils/log"
+	"google.golang.org/grpc"
+
+	"github.com/starkandwayne/shield/core"
+)
+
+// Serve starts the shield.CoreService gRPC listener on addr, wrapping c.
+// It installs the recovery, auth and per-tenant metrics interceptors on
+// both the unary and streaming paths, so a panic in one RPC handler
+// (WatchTasks included) can't take the shared listener down, matching
+// the failure isolation the HTTP v2 API gets from net/http's per-request
+// goroutines.
+func Serve(addr string, c *core.Core) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			recoveryUnaryInterceptor(),
+			authUnaryInterceptor(c),
+			metricsUnaryInterceptor(c),
+		),
+		grpc.ChainStreamInterceptor(
+			recoveryStreamInterceptor(),
+			authStreamInterceptor(c),
+			metricsStreamInterceptor(c),
+		),
+	)
+	RegisterServer(srv, c)
+
+	log.Infof("starting gRPC listener on %s", addr)
+	return srv.Serve(lis)
+}
@@ -0,0 +1,345 @@
+// Package grpc exposes the same operations as core.v2API (health, init/
+// unlock/rekey, systems, agents, tenants, annotations) over a gRPC
+// CoreService, for operators and integrations that want streaming or a
+// typed client instead of polling the HTTP v2 API. See shield.proto for
+// the wire contract.
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pborman/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/starkandwayne/shield/core"
+	"github.com/starkandwayne/shield/db"
+	"github.com/starkandwayne/shield/grpc/shieldpb"
+)
+
+// Server adapts a *core.Core to the shieldpb.CoreServiceServer interface.
+type Server struct {
+	shieldpb.UnimplementedCoreServiceServer
+
+	core *core.Core
+}
+
+// RegisterServer wraps c as a shield.CoreService and registers it on s.
+func RegisterServer(s *grpc.Server, c *core.Core) {
+	shieldpb.RegisterCoreServiceServer(s, &Server{core: c})
+}
+
+// requireRole resolves the *db.APIToken the auth interceptor stashed in
+// ctx and confirms it carries one of the allowed roles (or belongs to
+// the reserved system tenant), mirroring core.authorize on the HTTP v2
+// API. Every RPC but Health goes through that interceptor first, so a
+// missing token here means the interceptor chain isn't wired up, not
+// that the caller sent a bad request.
+func (s *Server) requireRole(ctx context.Context, allowed ...string) (*db.APIToken, error) {
+	token, ok := APITokenFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing authentication token")
+	}
+	authorized, err := s.core.AuthorizeToken(token, allowed...)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to resolve token tenant: %s", err)
+	}
+	if !authorized {
+		return nil, status.Error(codes.PermissionDenied, "token is not authorized for this operation")
+	}
+	return token, nil
+}
+
+// requireOwnsTarget mirrors the ownsTarget check the HTTP v2 API runs
+// before returning or mutating a specific system, so a token for one
+// tenant can't read or annotate another tenant's targets over gRPC.
+func (s *Server) requireOwnsTarget(token *db.APIToken, target *db.Target) error {
+	owns, err := s.core.OwnsTarget(token, target)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to resolve system ownership: %s", err)
+	}
+	if !owns {
+		return status.Error(codes.NotFound, "system not found")
+	}
+	return nil
+}
+
+func (s *Server) Health(ctx context.Context, _ *emptypb.Empty) (*shieldpb.HealthResponse, error) {
+	health, err := s.core.CheckHealth()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check SHIELD health: %s", err)
+	}
+	return &shieldpb.HealthResponse{Ok: true, Message: fmt.Sprintf("%v", health)}, nil
+}
+
+// Init, Unlock and Rekey require db.RoleTenantAdmin (unlike their HTTP
+// siblings, which don't authorize at all — POST /v2/init and
+// /v2/unlock run before any tenant/token can exist, so the HTTP API
+// gates them on nothing but the master password itself). gRPC's auth
+// interceptor always demands a valid token first, so there's no
+// equivalent bootstrap gap to preserve; requiring admin here closes the
+// privilege-escalation hole a merely-valid (e.g. read-only) token would
+// otherwise have over the whole vault.
+func (s *Server) Init(ctx context.Context, in *shieldpb.InitRequest) (*shieldpb.InitResponse, error) {
+	if _, err := s.requireRole(ctx, db.RoleTenantAdmin); err != nil {
+		return nil, err
+	}
+	if in.MasterPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "master_password is required")
+	}
+	ok, err := s.core.Initialize(in.MasterPassword)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to initialize the SHIELD core: %s", err)
+	}
+	if !ok {
+		return nil, status.Error(codes.FailedPrecondition, "this SHIELD core has already been initialized")
+	}
+	return &shieldpb.InitResponse{Ok: true}, nil
+}
+
+func (s *Server) Unlock(ctx context.Context, in *shieldpb.UnlockRequest) (*shieldpb.UnlockResponse, error) {
+	if _, err := s.requireRole(ctx, db.RoleTenantAdmin); err != nil {
+		return nil, err
+	}
+	if in.MasterPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "master_password is required")
+	}
+	ok, err := s.core.Unlock(in.MasterPassword)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to unlock the SHIELD core: %s", err)
+	}
+	if !ok {
+		return nil, status.Error(codes.FailedPrecondition, "this SHIELD core has not yet been initialized")
+	}
+	return &shieldpb.UnlockResponse{Ok: true}, nil
+}
+
+func (s *Server) Rekey(ctx context.Context, in *shieldpb.RekeyRequest) (*shieldpb.RekeyResponse, error) {
+	if _, err := s.requireRole(ctx, db.RoleTenantAdmin); err != nil {
+		return nil, err
+	}
+	if in.CurrentMasterPassword == "" || in.NewMasterPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "current_master_password and new_master_password are required")
+	}
+	if err := s.core.Rekey(in.CurrentMasterPassword, in.NewMasterPassword); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to rekey the SHIELD core: %s", err)
+	}
+	return &shieldpb.RekeyResponse{Ok: true}, nil
+}
+
+func (s *Server) ListSystems(ctx context.Context, in *shieldpb.ListSystemsRequest) (*shieldpb.ListSystemsResponse, error) {
+	token, err := s.requireRole(ctx, db.RoleBackupOperator, db.RoleRestoreOperator, db.RoleTenantAdmin, db.RoleReadOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &db.TargetFilter{
+		SearchName: in.Name,
+		ForPlugin:  in.Plugin,
+		ExactMatch: in.Exact,
+	}
+
+	tenant, err := s.core.DB.GetTenant(token.TenantUUID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve systems information: %s", err)
+	}
+	if tenant == nil || tenant.Name != db.SystemTenantName {
+		filter.ForTenant = token.TenantUUID.String()
+	}
+
+	targets, err := s.core.DB.GetAllTargets(filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve systems information: %s", err)
+	}
+
+	out := &shieldpb.ListSystemsResponse{Systems: make([]*shieldpb.System, len(targets))}
+	for i, target := range targets {
+		out.Systems[i] = &shieldpb.System{
+			Uuid:  target.UUID.String(),
+			Name:  target.Name,
+			Notes: target.Summary,
+		}
+	}
+	return out, nil
+}
+
+func (s *Server) GetSystem(ctx context.Context, in *shieldpb.GetSystemRequest) (*shieldpb.System, error) {
+	token, err := s.requireRole(ctx, db.RoleBackupOperator, db.RoleRestoreOperator, db.RoleTenantAdmin, db.RoleReadOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.core.DB.GetTarget(uuid.Parse(in.Uuid))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve system information: %s", err)
+	}
+	if target == nil {
+		return nil, status.Errorf(codes.NotFound, "system %s not found", in.Uuid)
+	}
+	if err := s.requireOwnsTarget(token, target); err != nil {
+		return nil, err
+	}
+	return &shieldpb.System{Uuid: target.UUID.String(), Name: target.Name, Notes: target.Summary}, nil
+}
+
+func (s *Server) ListAgents(ctx context.Context, _ *emptypb.Empty) (*shieldpb.ListAgentsResponse, error) {
+	if _, err := s.requireRole(ctx, db.RoleTenantAdmin, db.RoleReadOnly); err != nil {
+		return nil, err
+	}
+
+	agents, err := s.core.DB.GetAllAgents(nil)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve agent information: %s", err)
+	}
+	out := &shieldpb.ListAgentsResponse{AgentUuids: make([]string, len(agents))}
+	for i, a := range agents {
+		out.AgentUuids[i] = a.UUID.String()
+	}
+	return out, nil
+}
+
+// PreRegisterAgent isn't role-gated, matching its HTTP sibling (POST
+// /v2/agents): an agent pre-registers itself by the peer address it
+// connects from, not a tenant-scoped bearer token.
+func (s *Server) PreRegisterAgent(ctx context.Context, in *shieldpb.PreRegisterAgentRequest) (*emptypb.Empty, error) {
+	if in.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	if in.Port == 0 {
+		return nil, status.Error(codes.InvalidArgument, "port is required")
+	}
+
+	peer, ok := peerAddressFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Internal, "unable to determine remote peer address")
+	}
+
+	if err := s.core.DB.PreRegisterAgent(peer, in.Name, int(in.Port)); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to pre-register agent %s at %s:%d: %s", in.Name, peer, in.Port, err)
+	}
+	s.core.Events.Publish("agent.registered", "", "", in)
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) ListTenants(ctx context.Context, _ *emptypb.Empty) (*shieldpb.ListTenantsResponse, error) {
+	if _, err := s.requireRole(ctx, db.RoleTenantAdmin); err != nil {
+		return nil, err
+	}
+
+	tenants, err := s.core.DB.GetAllTenants()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve tenants information: %s", err)
+	}
+	out := &shieldpb.ListTenantsResponse{TenantUuids: make([]string, len(tenants))}
+	for i, t := range tenants {
+		out.TenantUuids[i] = t.UUID.String()
+	}
+	return out, nil
+}
+
+func (s *Server) PatchAnnotations(ctx context.Context, in *shieldpb.PatchAnnotationsRequest) (*emptypb.Empty, error) {
+	token, err := s.requireRole(ctx, db.RoleBackupOperator, db.RoleRestoreOperator, db.RoleTenantAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.core.DB.GetTarget(uuid.Parse(in.SystemUuid))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid or malformed target UUID: '%s'", in.SystemUuid)
+	}
+	if target == nil {
+		return nil, status.Errorf(codes.NotFound, "system %s not found", in.SystemUuid)
+	}
+	if err := s.requireOwnsTarget(token, target); err != nil {
+		return nil, err
+	}
+
+	for _, ann := range in.Annotations {
+		switch ann.Type {
+		case "task":
+			err = s.core.DB.AnnotateTargetTask(target.UUID, ann.Uuid, &db.TaskAnnotation{
+				Disposition: ann.Disposition,
+				Notes:       ann.Notes,
+				Clear:       ann.Clear,
+			})
+		case "archive":
+			err = s.core.DB.AnnotateTargetArchive(target.UUID, ann.Uuid, ann.Notes)
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unrecognized system annotation type '%s'", ann.Type)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to annotate %s %s: %s", ann.Type, ann.Uuid, err)
+		}
+		s.core.Events.Publish(ann.Type+".annotated", target.TenantUUID.String(), target.UUID.String(), ann)
+	}
+
+	_ = s.core.DB.MarkTasksIrrelevant()
+	return &emptypb.Empty{}, nil
+}
+
+// WatchTasks streams v2SystemTask-equivalent updates for a system as they
+// change in the DB, so callers can tail backup/restore progress instead of
+// polling GetSystem in a loop. It subscribes to the core's task change
+// feed and exits when the stream's context is cancelled.
+func (s *Server) WatchTasks(in *shieldpb.WatchTasksRequest, stream shieldpb.CoreService_WatchTasksServer) error {
+	token, err := s.requireRole(stream.Context(), db.RoleBackupOperator, db.RoleRestoreOperator, db.RoleTenantAdmin, db.RoleReadOnly)
+	if err != nil {
+		return err
+	}
+	if in.SystemUuid != "" {
+		target, err := s.core.DB.GetTarget(uuid.Parse(in.SystemUuid))
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to resolve system %s: %s", in.SystemUuid, err)
+		}
+		if target == nil {
+			return status.Errorf(codes.NotFound, "system %s not found", in.SystemUuid)
+		}
+		if err := s.requireOwnsTarget(token, target); err != nil {
+			return err
+		}
+	}
+
+	ch, cancel := s.core.WatchTasks(in.SystemUuid)
+	defer cancel()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case task, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if in.SystemUuid == "" {
+				target, err := s.core.DB.GetTarget(task.TargetUUID)
+				if err != nil || target == nil {
+					continue
+				}
+				if owns, err := s.core.OwnsTarget(token, target); err != nil || !owns {
+					continue
+				}
+			}
+			if err := stream.Send(toPBTask(task)); err != nil {
+				return status.Errorf(codes.Internal, "failed to send task update: %s", err)
+			}
+		}
+	}
+}
+
+func toPBTask(task *db.Task) *shieldpb.SystemTask {
+	out := &shieldpb.SystemTask{
+		Uuid:   task.UUID.String(),
+		Type:   task.Op,
+		Status: task.Status,
+		Owner:  task.Owner,
+		Ok:     task.OK,
+		Notes:  task.Notes,
+	}
+	if t := task.StartedAt.Time(); !t.IsZero() {
+		out.StartedAt = t.Unix()
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+// Package shieldpb holds the Go types for shield.proto's CoreService.
+//
+// These are hand-maintained, not protoc-gen-go output: this tree has no
+// protoc/buf toolchain wired into its build yet, so there's nothing to
+// regenerate from. shield.proto is the source of truth for the wire
+// contract; if you change a message here, update shield.proto to match.
+// Once a real toolchain is available, these two files should be deleted
+// and regenerated properly with `make proto`.
+package shieldpb
+
+type HealthResponse struct {
+	Ok      bool   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+type InitRequest struct {
+	MasterPassword string `protobuf:"bytes,1,opt,name=master_password,json=masterPassword,proto3" json:"master_password,omitempty"`
+}
+type InitResponse struct {
+	Ok bool `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+}
+
+type UnlockRequest struct {
+	MasterPassword string `protobuf:"bytes,1,opt,name=master_password,json=masterPassword,proto3" json:"master_password,omitempty"`
+}
+type UnlockResponse struct {
+	Ok bool `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+}
+
+type RekeyRequest struct {
+	CurrentMasterPassword string `protobuf:"bytes,1,opt,name=current_master_password,json=currentMasterPassword,proto3" json:"current_master_password,omitempty"`
+	NewMasterPassword     string `protobuf:"bytes,2,opt,name=new_master_password,json=newMasterPassword,proto3" json:"new_master_password,omitempty"`
+}
+type RekeyResponse struct {
+	Ok bool `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+}
+
+type ListSystemsRequest struct {
+	Name   string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Plugin string `protobuf:"bytes,2,opt,name=plugin,proto3" json:"plugin,omitempty"`
+	Exact  bool   `protobuf:"varint,3,opt,name=exact,proto3" json:"exact,omitempty"`
+}
+type ListSystemsResponse struct {
+	Systems []*System `protobuf:"bytes,1,rep,name=systems,proto3" json:"systems,omitempty"`
+}
+
+type GetSystemRequest struct {
+	Uuid string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+}
+
+type System struct {
+	Uuid  string        `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Name  string        `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Notes string        `protobuf:"bytes,3,opt,name=notes,proto3" json:"notes,omitempty"`
+	Ok    bool          `protobuf:"varint,4,opt,name=ok,proto3" json:"ok,omitempty"`
+	Tasks []*SystemTask `protobuf:"bytes,5,rep,name=tasks,proto3" json:"tasks,omitempty"`
+}
+
+type SystemArchive struct {
+	Uuid     string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Schedule string `protobuf:"bytes,2,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	TakenAt  int64  `protobuf:"varint,3,opt,name=taken_at,json=takenAt,proto3" json:"taken_at,omitempty"`
+	Expiry   int32  `protobuf:"varint,4,opt,name=expiry,proto3" json:"expiry,omitempty"`
+	Size     int64  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	Ok       bool   `protobuf:"varint,6,opt,name=ok,proto3" json:"ok,omitempty"`
+	Notes    string `protobuf:"bytes,7,opt,name=notes,proto3" json:"notes,omitempty"`
+}
+
+type SystemTask struct {
+	Uuid      string         `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Type      string         `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Status    string         `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Owner     string         `protobuf:"bytes,4,opt,name=owner,proto3" json:"owner,omitempty"`
+	StartedAt int64          `protobuf:"varint,5,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	Ok        bool           `protobuf:"varint,6,opt,name=ok,proto3" json:"ok,omitempty"`
+	Notes     string         `protobuf:"bytes,7,opt,name=notes,proto3" json:"notes,omitempty"`
+	Archive   *SystemArchive `protobuf:"bytes,8,opt,name=archive,proto3" json:"archive,omitempty"`
+}
+
+type ListAgentsResponse struct {
+	AgentUuids []string `protobuf:"bytes,1,rep,name=agent_uuids,json=agentUuids,proto3" json:"agent_uuids,omitempty"`
+}
+
+type PreRegisterAgentRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Port int32  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+}
+
+type ListTenantsResponse struct {
+	TenantUuids []string `protobuf:"bytes,1,rep,name=tenant_uuids,json=tenantUuids,proto3" json:"tenant_uuids,omitempty"`
+}
+
+type Annotation struct {
+	Type        string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Uuid        string `protobuf:"bytes,2,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Disposition string `protobuf:"bytes,3,opt,name=disposition,proto3" json:"disposition,omitempty"`
+	Notes       string `protobuf:"bytes,4,opt,name=notes,proto3" json:"notes,omitempty"`
+	Clear       string `protobuf:"bytes,5,opt,name=clear,proto3" json:"clear,omitempty"`
+}
+
+type PatchAnnotationsRequest struct {
+	SystemUuid  string        `protobuf:"bytes,1,opt,name=system_uuid,json=systemUuid,proto3" json:"system_uuid,omitempty"`
+	Annotations []*Annotation `protobuf:"bytes,2,rep,name=annotations,proto3" json:"annotations,omitempty"`
+}
+
+type WatchTasksRequest struct {
+	SystemUuid string `protobuf:"bytes,1,opt,name=system_uuid,json=systemUuid,proto3" json:"system_uuid,omitempty"`
+}
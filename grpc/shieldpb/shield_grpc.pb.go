@@ -0,0 +1,274 @@
+// shield_grpc.pb.go holds the server-side gRPC plumbing for
+// CoreService: the server interface, the streaming handle, and the
+// ServiceDesc grpc.Server dispatches RPCs through. Like shield.pb.go,
+// this is hand-maintained against shield.proto rather than real
+// protoc-gen-go-grpc output — see shieldpb's package comment.
+package shieldpb
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// CoreServiceServer is the server API for CoreService.
+type CoreServiceServer interface {
+	Health(context.Context, *emptypb.Empty) (*HealthResponse, error)
+	Init(context.Context, *InitRequest) (*InitResponse, error)
+	Unlock(context.Context, *UnlockRequest) (*UnlockResponse, error)
+	Rekey(context.Context, *RekeyRequest) (*RekeyResponse, error)
+	ListSystems(context.Context, *ListSystemsRequest) (*ListSystemsResponse, error)
+	GetSystem(context.Context, *GetSystemRequest) (*System, error)
+	ListAgents(context.Context, *emptypb.Empty) (*ListAgentsResponse, error)
+	PreRegisterAgent(context.Context, *PreRegisterAgentRequest) (*emptypb.Empty, error)
+	ListTenants(context.Context, *emptypb.Empty) (*ListTenantsResponse, error)
+	PatchAnnotations(context.Context, *PatchAnnotationsRequest) (*emptypb.Empty, error)
+	WatchTasks(*WatchTasksRequest, CoreService_WatchTasksServer) error
+}
+
+// CoreService_WatchTasksServer is the server-streaming handle for WatchTasks.
+type CoreService_WatchTasksServer interface {
+	Send(*SystemTask) error
+	grpc.ServerStream
+}
+
+// UnimplementedCoreServiceServer can be embedded in server implementations
+// to satisfy CoreServiceServer for methods not yet implemented; it panics
+// only if those unimplemented methods are actually called.
+type UnimplementedCoreServiceServer struct{}
+
+func (UnimplementedCoreServiceServer) Health(context.Context, *emptypb.Empty) (*HealthResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Health not implemented")
+}
+func (UnimplementedCoreServiceServer) Init(context.Context, *InitRequest) (*InitResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Init not implemented")
+}
+func (UnimplementedCoreServiceServer) Unlock(context.Context, *UnlockRequest) (*UnlockResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Unlock not implemented")
+}
+func (UnimplementedCoreServiceServer) Rekey(context.Context, *RekeyRequest) (*RekeyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Rekey not implemented")
+}
+func (UnimplementedCoreServiceServer) ListSystems(context.Context, *ListSystemsRequest) (*ListSystemsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSystems not implemented")
+}
+func (UnimplementedCoreServiceServer) GetSystem(context.Context, *GetSystemRequest) (*System, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSystem not implemented")
+}
+func (UnimplementedCoreServiceServer) ListAgents(context.Context, *emptypb.Empty) (*ListAgentsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListAgents not implemented")
+}
+func (UnimplementedCoreServiceServer) PreRegisterAgent(context.Context, *PreRegisterAgentRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method PreRegisterAgent not implemented")
+}
+func (UnimplementedCoreServiceServer) ListTenants(context.Context, *emptypb.Empty) (*ListTenantsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTenants not implemented")
+}
+func (UnimplementedCoreServiceServer) PatchAnnotations(context.Context, *PatchAnnotationsRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method PatchAnnotations not implemented")
+}
+func (UnimplementedCoreServiceServer) WatchTasks(*WatchTasksRequest, CoreService_WatchTasksServer) error {
+	return status.Error(codes.Unimplemented, "method WatchTasks not implemented")
+}
+
+func _CoreService_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/shield.CoreService/Health"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).Health(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_Init_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).Init(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/shield.CoreService/Init"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).Init(ctx, req.(*InitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_Unlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).Unlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/shield.CoreService/Unlock"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).Unlock(ctx, req.(*UnlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_Rekey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RekeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).Rekey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/shield.CoreService/Rekey"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).Rekey(ctx, req.(*RekeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_ListSystems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSystemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).ListSystems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/shield.CoreService/ListSystems"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).ListSystems(ctx, req.(*ListSystemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_GetSystem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSystemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).GetSystem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/shield.CoreService/GetSystem"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).GetSystem(ctx, req.(*GetSystemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_ListAgents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).ListAgents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/shield.CoreService/ListAgents"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).ListAgents(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_PreRegisterAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PreRegisterAgentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).PreRegisterAgent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/shield.CoreService/PreRegisterAgent"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).PreRegisterAgent(ctx, req.(*PreRegisterAgentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_ListTenants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).ListTenants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/shield.CoreService/ListTenants"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).ListTenants(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_PatchAnnotations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchAnnotationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).PatchAnnotations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/shield.CoreService/PatchAnnotations"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).PatchAnnotations(ctx, req.(*PatchAnnotationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_WatchTasks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	in := new(WatchTasksRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(CoreServiceServer).WatchTasks(in, &coreServiceWatchTasksServer{stream})
+}
+
+type coreServiceWatchTasksServer struct {
+	grpc.ServerStream
+}
+
+func (s *coreServiceWatchTasksServer) Send(m *SystemTask) error {
+	return s.ServerStream.SendMsg(m)
+}
+
+// CoreService_ServiceDesc is the grpc.ServiceDesc grpc.Server dispatches
+// CoreService RPCs through; RegisterCoreServiceServer registers it
+// directly, the same way protoc-gen-go-grpc output does.
+var CoreService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "shield.CoreService",
+	HandlerType: (*CoreServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Health", Handler: _CoreService_Health_Handler},
+		{MethodName: "Init", Handler: _CoreService_Init_Handler},
+		{MethodName: "Unlock", Handler: _CoreService_Unlock_Handler},
+		{MethodName: "Rekey", Handler: _CoreService_Rekey_Handler},
+		{MethodName: "ListSystems", Handler: _CoreService_ListSystems_Handler},
+		{MethodName: "GetSystem", Handler: _CoreService_GetSystem_Handler},
+		{MethodName: "ListAgents", Handler: _CoreService_ListAgents_Handler},
+		{MethodName: "PreRegisterAgent", Handler: _CoreService_PreRegisterAgent_Handler},
+		{MethodName: "ListTenants", Handler: _CoreService_ListTenants_Handler},
+		{MethodName: "PatchAnnotations", Handler: _CoreService_PatchAnnotations_Handler},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTasks",
+			Handler:       _CoreService_WatchTasks_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "shield.proto",
+}
+
+// RegisterCoreServiceServer registers srv with s.
+func RegisterCoreServiceServer(s *grpc.Server, srv CoreServiceServer) {
+	s.RegisterService(&CoreService_ServiceDesc, srv)
+}